@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BahasaDefault => bahasa label tingkatan capaian jika ?lang= tidak diisi
+const BahasaDefault = "id"
+
+// AchievementLevelInput adalah payload untuk pembuatan / update tingkatan capaian
+type AchievementLevelInput struct {
+	Code      string            `json:"code" binding:"required"`
+	Point     float64           `json:"point"`
+	SortOrder int               `json:"sort_order"`
+	Labels    map[string]string `json:"labels" binding:"required"` // bahasa => label
+}
+
+// TingkatCapaian => tingkatan capaian dengan label pada satu bahasa (untuk front-end)
+type TingkatCapaian struct {
+	Code  string  `json:"code"`
+	Label string  `json:"label"`
+	Point float64 `json:"point"`
+}
+
+// GetKPIAchievementList - GET /api/kpi_achievement_list?lang=
+// Mengembalikan daftar tingkatan penilaian untuk front-end (label sesuai bahasa, fallback ke code)
+func GetKPIAchievementList(c *gin.Context) {
+	levels, err := tingkatCapaian()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tingkatan capaian"})
+		return
+	}
+
+	lang := c.DefaultQuery("lang", BahasaDefault)
+	list := []TingkatCapaian{}
+	for _, level := range levels {
+		item := TingkatCapaian{Code: level.Code, Label: level.Code, Point: level.Point}
+		for _, l := range level.Labels {
+			if l.Language == lang {
+				item.Label = l.Label
+			}
+		}
+		list = append(list, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetAchievementLevels - GET /api/achievement-levels
+func GetAchievementLevels(c *gin.Context) {
+	levels, err := tingkatCapaian()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tingkatan capaian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": levels})
+}
+
+// CreateAchievementLevel - POST /api/achievement-levels
+func CreateAchievementLevel(c *gin.Context) {
+	var input AchievementLevelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var level models.AchievementLevel
+	if !isiTingkatCapaian(c, &level, input) {
+		return
+	}
+
+	if err := db.Create(&level).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code tingkatan capaian " + level.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tingkatan capaian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": level})
+}
+
+// UpdateAchievementLevel - PUT /api/achievement-levels/:id
+// Label lama diganti seluruhnya dengan label pada input.
+func UpdateAchievementLevel(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var level models.AchievementLevel
+	if err := db.First(&level, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tingkatan capaian tidak ditemukan"})
+		return
+	}
+
+	var input AchievementLevelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isiTingkatCapaian(c, &level, input) {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("achievement_level_id = ?", level.ID).Delete(&models.AchievementLevelLabel{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(&level).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code tingkatan capaian " + level.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui tingkatan capaian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": level})
+}
+
+// DeleteAchievementLevel - DELETE /api/achievement-levels/:id
+// Dihapus permanen agar code bisa dipakai ulang (poin penilaian KPI yang sudah ada tetap tersimpan).
+func DeleteAchievementLevel(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var level models.AchievementLevel
+	if err := db.First(&level, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tingkatan capaian tidak ditemukan"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("achievement_level_id = ?", level.ID).Delete(&models.AchievementLevelLabel{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&level).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tingkatan capaian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// isiTingkatCapaian memvalidasi input lalu mengisi field tingkatan capaian beserta labelnya.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiTingkatCapaian(c *gin.Context, level *models.AchievementLevel, input AchievementLevelInput) bool {
+	if input.Point < 0 || input.Point > SkorMaksimal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "point harus di antara 0 dan 5"})
+		return false
+	}
+	if len(input.Labels) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal satu label wajib diisi"})
+		return false
+	}
+
+	level.Code = input.Code
+	level.Point = input.Point
+	level.SortOrder = input.SortOrder
+	level.Labels = nil
+	for lang, label := range input.Labels {
+		level.Labels = append(level.Labels, models.AchievementLevelLabel{Language: lang, Label: label})
+	}
+	return true
+}
+
+// tingkatCapaian => semua tingkatan capaian beserta labelnya, terurut
+func tingkatCapaian() ([]models.AchievementLevel, error) {
+	var levels []models.AchievementLevel
+	err := db.Preload("Labels").Order("sort_order asc, point asc").Find(&levels).Error
+	return levels, err
+}
+
+// poinCapaian mengubah code tingkatan capaian menjadi poin. ok = false jika code tidak dikenal;
+// valid berisi daftar code yang berlaku (untuk pesan error).
+func poinCapaian(code string) (poin float64, ok bool, valid []string, err error) {
+	levels, err := tingkatCapaian()
+	if err != nil {
+		return 0, false, nil, err
+	}
+	valid = []string{}
+	for _, level := range levels {
+		if level.Code == code {
+			return level.Point, true, nil, nil
+		}
+		valid = append(valid, level.Code)
+	}
+	return 0, false, valid, nil
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Batas iterasi & toleransi untuk metode power iteration (eigenvector utama).
+const (
+	ahpMaksIterasi = 1000
+	ahpToleransi   = 1e-10
+)
+
+// BatasRasioKonsistensi => matriks dengan CR di atas nilai ini dianggap tidak konsisten (Saaty)
+const BatasRasioKonsistensi = 0.1
+
+// randomIndexSaaty => tabel Random Index (RI) Saaty berdasarkan ukuran matriks n (indeks = n)
+var randomIndexSaaty = []float64{0, 0, 0, 0.58, 0.90, 1.12, 1.24, 1.32, 1.41, 1.45, 1.49, 1.51, 1.48, 1.56, 1.57, 1.59}
+
+// KonsistensiAHP merepresentasikan hasil uji konsistensi matriks perbandingan berpasangan.
+type KonsistensiAHP struct {
+	LambdaMax         float64       `json:"lambda_max"`
+	ConsistencyIndex  float64       `json:"consistency_index"`
+	RandomIndex       float64       `json:"random_index"`
+	ConsistencyRatio  float64       `json:"consistency_ratio"`
+	Consistent        bool          `json:"consistent"`
+	InconsistentPairs []PasanganAHP `json:"inconsistent_pairs"`
+}
+
+// PasanganAHP merepresentasikan satu pasangan kriteria beserta penyimpangannya dari bobot hasil AHP.
+type PasanganAHP struct {
+	KriteriaA      string  `json:"kriteria_a"`
+	KriteriaB      string  `json:"kriteria_b"`
+	Nilai          float64 `json:"nilai"`           // nilai yang diinput pada matriks [A][B]
+	NilaiKonsisten float64 `json:"nilai_konsisten"` // nilai yang konsisten dengan bobot (wA / wB)
+	Deviasi        float64 `json:"deviasi"`         // |ln(nilai / nilai_konsisten)|
+}
+
+// HitungBobotAHP menghitung vektor prioritas (bobot) dari matriks perbandingan berpasangan
+// menggunakan metode eigenvector (power iteration). Hasil bobot selalu berjumlah 1.
+func HitungBobotAHP(matriks [][]float64) ([]float64, error) {
+	if err := validasiMatriksAHP(matriks); err != nil {
+		return nil, err
+	}
+
+	n := len(matriks)
+	bobot := make([]float64, n)
+	for i := range bobot {
+		bobot[i] = 1 / float64(n)
+	}
+
+	for iter := 0; iter < ahpMaksIterasi; iter++ {
+		baru := kaliMatriksVektor(matriks, bobot)
+
+		var total float64
+		for _, v := range baru {
+			total += v
+		}
+		for i := range baru {
+			baru[i] /= total
+		}
+
+		selisih := 0.0
+		for i := range baru {
+			selisih = math.Max(selisih, math.Abs(baru[i]-bobot[i]))
+		}
+		bobot = baru
+		if selisih < ahpToleransi {
+			break
+		}
+	}
+
+	return bobot, nil
+}
+
+// validasiMatriksAHP memastikan matriks persegi, bernilai positif, diagonal = 1 dan resiprokal.
+func validasiMatriksAHP(matriks [][]float64) error {
+	n := len(matriks)
+	if n == 0 {
+		return errors.New("matriks perbandingan berpasangan kosong")
+	}
+	for i, baris := range matriks {
+		if len(baris) != n {
+			return fmt.Errorf("matriks harus persegi (%dx%d), baris %d memiliki %d kolom", n, n, i+1, len(baris))
+		}
+		for j, v := range baris {
+			if v <= 0 {
+				return fmt.Errorf("nilai matriks [%d][%d] harus lebih dari 0", i+1, j+1)
+			}
+		}
+		if math.Abs(baris[i]-1) > 1e-6 {
+			return fmt.Errorf("nilai diagonal [%d][%d] harus 1", i+1, i+1)
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if math.Abs(matriks[i][j]*matriks[j][i]-1) > 1e-2 {
+				return fmt.Errorf("nilai [%d][%d] dan [%d][%d] harus saling berkebalikan (resiprokal)", i+1, j+1, j+1, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// kaliMatriksVektor => hasil perkalian matriks A dengan vektor w
+func kaliMatriksVektor(matriks [][]float64, w []float64) []float64 {
+	hasil := make([]float64, len(matriks))
+	for i, baris := range matriks {
+		for j, v := range baris {
+			hasil[i] += v * w[j]
+		}
+	}
+	return hasil
+}
+
+// HitungKonsistensiAHP menghitung lambda max, Consistency Index (CI) dan Consistency Ratio (CR)
+// dari matriks & bobot hasil HitungBobotAHP, serta daftar pasangan yang paling tidak konsisten.
+func HitungKonsistensiAHP(matriks [][]float64, bobot []float64, nama []string) KonsistensiAHP {
+	n := len(matriks)
+	hasil := KonsistensiAHP{Consistent: true, InconsistentPairs: []PasanganAHP{}}
+	if n == 0 {
+		return hasil
+	}
+
+	// lambda max = rata-rata (A.w)_i / w_i
+	aw := kaliMatriksVektor(matriks, bobot)
+	for i := range aw {
+		hasil.LambdaMax += aw[i] / bobot[i]
+	}
+	hasil.LambdaMax /= float64(n)
+
+	// Matriks 1x1 dan 2x2 selalu konsisten (RI = 0)
+	if n > 2 {
+		hasil.ConsistencyIndex = (hasil.LambdaMax - float64(n)) / float64(n-1)
+		hasil.RandomIndex = randomIndexSaaty[len(randomIndexSaaty)-1]
+		if n < len(randomIndexSaaty) {
+			hasil.RandomIndex = randomIndexSaaty[n]
+		}
+		hasil.ConsistencyRatio = hasil.ConsistencyIndex / hasil.RandomIndex
+	}
+	hasil.Consistent = hasil.ConsistencyRatio <= BatasRasioKonsistensi
+
+	// Urutkan pasangan berdasarkan penyimpangan terbesar
+	var pasangan []PasanganAHP
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			konsisten := bobot[i] / bobot[j]
+			deviasi := math.Abs(math.Log(matriks[i][j] / konsisten))
+			if deviasi < 1e-6 {
+				continue
+			}
+			pasangan = append(pasangan, PasanganAHP{
+				KriteriaA:      nama[i],
+				KriteriaB:      nama[j],
+				Nilai:          matriks[i][j],
+				NilaiKonsisten: RoundFloat(konsisten, 3),
+				Deviasi:        RoundFloat(deviasi, 3),
+			})
+		}
+	}
+	sort.SliceStable(pasangan, func(a, b int) bool { return pasangan[a].Deviasi > pasangan[b].Deviasi })
+	if len(pasangan) > 3 {
+		pasangan = pasangan[:3]
+	}
+	if !hasil.Consistent {
+		hasil.InconsistentPairs = pasangan
+	}
+
+	hasil.LambdaMax = RoundFloat(hasil.LambdaMax, 4)
+	hasil.ConsistencyIndex = RoundFloat(hasil.ConsistencyIndex, 4)
+	hasil.ConsistencyRatio = RoundFloat(hasil.ConsistencyRatio, 4)
+	return hasil
+}
@@ -61,3 +61,17 @@ func Login(c *gin.Context) {
 		"role":  employee.Role,
 	})
 }
+
+// idPenggunaLogin mengambil employee_id dari token JWT (diset oleh middleware JWTAuth).
+// Mengembalikan 0 jika route tidak memakai JWTAuth.
+func idPenggunaLogin(c *gin.Context) uint {
+	v, ok := c.Get("employee_id")
+	if !ok {
+		return 0
+	}
+	// jwt.MapClaims menyimpan angka sebagai float64
+	if id, ok := v.(float64); ok {
+		return uint(id)
+	}
+	return 0
+}
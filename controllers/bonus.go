@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
 
 	"bonus/models"
 
@@ -9,15 +13,32 @@ import (
 	"gorm.io/gorm"
 )
 
-// AHPRequest merepresentasikan payload untuk perhitungan bonus (jika menggunakan bobot AHP).
+// AHPRequest merepresentasikan payload untuk perhitungan bonus dengan bobot AHP.
+// Bobot diturunkan dari matriks perbandingan berpasangan (skala Saaty 1-9) antar kriteria,
+// urutan baris/kolom matriks mengikuti urutan Criteria.
+// Contoh: {"criteria": ["KPI", "Kondite", "PenambahPoin"], "pairwise_matrix": [[1, 3, 5], [0.3333, 1, 3], [0.2, 0.3333, 1]]}
 type AHPRequest struct {
+	Criteria       []string    `json:"criteria"`
+	PairwiseMatrix [][]float64 `json:"pairwise_matrix"`
+
+	// Periode evaluasi yang dihitung (0 => seluruh data)
+	PeriodID uint `json:"period_id"`
+
+	// Tetap proses walaupun consistency ratio > 0.1
+	AllowInconsistent bool `json:"allow_inconsistent"`
+
+	// Alternatif tanpa matriks: bobot langsung, mis. {"KPI": 0.5, "Kondite": 0.3, "PenambahPoin": 0.2}
 	CriteriaWeights map[string]float64 `json:"criteria_weights"`
-	// Contoh pemakaian: {"KPI": 0.5, "Kondite": 0.3, "PenambahPoin": 0.2}
 }
 
-// GetBonus - GET /api/bonus
+// GetBonus - GET /api/bonus?period_id=
 // Mengambil data bonus setiap pegawai (dihitung langsung).
 func GetBonus(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	// Pastikan variabel db adalah milik package config atau global (misalnya config.DB).
 	// Di sini diasumsikan ada variabel global db = config.DB
 	var employees []models.Employee
@@ -32,7 +53,7 @@ func GetBonus(c *gin.Context) {
 	for _, emp := range employees {
 		// Ambil data KPI milik pegawai ini
 		var kpis []models.KPI
-		if err := db.Where("employee_id = ?", emp.ID).Find(&kpis).Error; err != nil && err != gorm.ErrRecordNotFound {
+		if err := filterPeriode(db.Where("employee_id = ?", emp.ID), periode).Find(&kpis).Error; err != nil && err != gorm.ErrRecordNotFound {
 			continue
 		}
 
@@ -59,7 +80,8 @@ func GetBonus(c *gin.Context) {
 }
 
 // CalculateBonus - POST /api/bonus/calculate
-// Menghitung bonus berdasarkan bobot kriteria (AHP) atau logika lain.
+// Menghitung bonus berdasarkan bobot kriteria hasil AHP.
+// Skor akhir pegawai = jumlah (bobot kriteria * skor kriteria), lalu dipetakan ke skala & multiplier gaji.
 func CalculateBonus(c *gin.Context) {
 	var req AHPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -67,6 +89,33 @@ func CalculateBonus(c *gin.Context) {
 		return
 	}
 
+	periode, ok := ambilPeriode(c, req.PeriodID)
+	if !ok {
+		return
+	}
+
+	bobot, konsistensi, err := bobotDariRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Tolak matriks yang tidak konsisten (CR > 0.1) kecuali diizinkan secara eksplisit
+	if konsistensi != nil && !konsistensi.Consistent && !req.AllowInconsistent {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":       "Matriks perbandingan tidak konsisten (CR > 0.1), periksa kembali pasangan kriteria berikut",
+			"consistency": konsistensi,
+		})
+		return
+	}
+
+	// Tabel skala yang berlaku untuk periode
+	_, bands, err := SkalaUntukPeriode(periode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tabel skala"})
+		return
+	}
+
 	// Ambil seluruh pegawai
 	var employees []models.Employee
 	if err := db.Find(&employees).Error; err != nil {
@@ -78,31 +127,206 @@ func CalculateBonus(c *gin.Context) {
 
 	// Lakukan perhitungan bonus untuk setiap pegawai
 	for _, emp := range employees {
-		var kpis []models.KPI
-		if err := db.Where("employee_id = ?", emp.ID).Find(&kpis).Error; err != nil && err != gorm.ErrRecordNotFound {
+		perusahaan, dept, ind, err := HitungKPIPegawai(emp.ID, periode)
+		if err != nil {
 			continue
 		}
+		pengurang, _ := HitungPengurangPoin(emp.ID, periode)
+		penambah, _ := HitungPenambahPoin(emp.ID, periode)
 
-		// Contoh perhitungan totalScore = penjumlahan Score KPI
-		totalScore := 0.0
-		for _, kpi := range kpis {
-			totalScore += kpi.Score
+		skor, err := SkorKriteriaPegawai(emp.ID, periode, perusahaan+dept+ind, pengurang, penambah, namaKriteria(bobot))
+		if err != nil {
+			continue
 		}
 
-		// Jika menggunakan bobot AHP, Anda bisa mengalikan totalScore dengan bobot tertentu:
-		// Misalnya: bonus = totalScore * req.CriteriaWeights["KPI"] * KONSTANTA
-		// Di sini untuk contoh sederhana, kita abaikan bobot:
-		bonus := totalScore * 1000
+		// Skor akhir = sum(bobot_i * skor_i)
+		totalScore := SkorTerbobot(bobot, skor)
+
+		band := TentukanBand(bands, totalScore)
+		multiplier := HitungMultiplier(bands, totalScore, modeSkalaPeriode(periode))
+		gaji := float64(emp.Salary)
+		bonus := HitungBonusBand(band, gaji, multiplier)
 
 		// Tambahkan ke results
 		results = append(results, gin.H{
-			"employee_id": emp.ID,
-			"name":        emp.Name,
-			"total_score": totalScore,
-			"bonus":       bonus,
+			"employee_id":     emp.ID,
+			"name":            emp.Name,
+			"criteria_scores": skor,
+			"total_score":     RoundFloat(totalScore, 3),
+			"skala":           band.Label,
+			"multiplier":      RoundFloat(multiplier, 3),
+			"gaji":            gaji,
+			"bonus":           bonus,
 		})
 	}
 
-	// Kembalikan hasil perhitungan bonus
-	c.JSON(http.StatusOK, gin.H{"data": results})
+	// Kembalikan bobot hasil AHP beserta hasil perhitungan bonus
+	c.JSON(http.StatusOK, gin.H{"weights": bobot, "consistency": konsistensi, "data": results})
+}
+
+// bobotDariRequest menurunkan bobot kriteria dari matriks berpasangan (AHP),
+// memakai criteria_weights bila matriks tidak dikirim, atau bobot kriteria tersimpan
+// (tabel criteria) bila keduanya kosong. Hasil konsistensi hanya ada untuk matriks AHP.
+func bobotDariRequest(req AHPRequest) (map[string]float64, *KonsistensiAHP, error) {
+	if len(req.PairwiseMatrix) == 0 {
+		bobot := req.CriteriaWeights
+		if len(bobot) == 0 {
+			tersimpan, err := BobotKriteriaTersimpan()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(tersimpan) == 0 {
+				return nil, nil, errors.New("pairwise_matrix atau criteria_weights wajib diisi (belum ada kriteria tersimpan)")
+			}
+			bobot = tersimpan
+		}
+		if err := validasiBobotRequest(bobot); err != nil {
+			return nil, nil, err
+		}
+		return bobot, nil, nil
+	}
+
+	if len(req.Criteria) != len(req.PairwiseMatrix) {
+		return nil, nil, errors.New("jumlah criteria harus sama dengan ukuran pairwise_matrix")
+	}
+	if err := validasiDaftarKriteria(req.Criteria); err != nil {
+		return nil, nil, err
+	}
+
+	w, err := HitungBobotAHP(req.PairwiseMatrix)
+	if err != nil {
+		return nil, nil, err
+	}
+	bobot := make(map[string]float64, len(w))
+	for i, nama := range req.Criteria {
+		bobot[nama] = w[i]
+	}
+	konsistensi := HitungKonsistensiAHP(req.PairwiseMatrix, w, req.Criteria)
+	return bobot, &konsistensi, nil
+}
+
+// validasiBobotRequest => error jika kriteria tidak dikenal / duplikat, bobot di luar 0..1,
+// atau total bobot tidak sama dengan 1
+func validasiBobotRequest(bobot map[string]float64) error {
+	nama := make([]string, 0, len(bobot))
+	for k, w := range bobot {
+		if w < 0 || w > 1 {
+			return fmt.Errorf("bobot kriteria %q harus di antara 0 dan 1", k)
+		}
+		nama = append(nama, k)
+	}
+	if err := validasiDaftarKriteria(nama); err != nil {
+		return err
+	}
+	return validasiTotalBobot(bobot)
+}
+
+// validasiDaftarKriteria => error jika ada kriteria yang tidak dikenal atau muncul lebih dari sekali
+// (perbandingan memakai kunciKriteria, sehingga "KPI" dan "kpi" dianggap sama)
+func validasiDaftarKriteria(kriteria []string) error {
+	sudahAda := map[string]bool{}
+	for _, nama := range kriteria {
+		kunci := kunciKriteria(nama)
+		if sudahAda[kunci] {
+			return fmt.Errorf("kriteria %q duplikat", nama)
+		}
+		sudahAda[kunci] = true
+		if err := validasiKriteria(nama); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Kriteria bawaan yang skornya dapat dihitung dari data pegawai (skala 0-5).
+// Kriteria lain diambil dari tabel criteria, skornya dari rata-rata evaluations.
+const (
+	KriteriaKPI          = "kpi"
+	KriteriaKondite      = "kondite"
+	KriteriaPenambahPoin = "penambahpoin"
+)
+
+// SkorMaksimal adalah skor tertinggi pada skala penilaian (Exceptional).
+const SkorMaksimal = 5.0
+
+// kunciKriteria menormalkan nama kriteria ("KPI", "Penambah Poin", dsb.) menjadi kunci pencarian
+func kunciKriteria(nama string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(nama), " ", ""))
+}
+
+// kriteriaBawaan => true jika skor kriteria dihitung langsung dari KPI/kondite/penambah poin
+func kriteriaBawaan(nama string) bool {
+	switch kunciKriteria(nama) {
+	case KriteriaKPI, KriteriaKondite, KriteriaPenambahPoin:
+		return true
+	}
+	return false
+}
+
+// validasiKriteria => error jika kriteria bukan kriteria bawaan dan tidak ada di tabel criteria
+func validasiKriteria(nama string) error {
+	if kriteriaBawaan(nama) {
+		return nil
+	}
+	var criterion models.Criterion
+	if err := db.Where("name = ?", nama).First(&criterion).Error; err != nil {
+		return fmt.Errorf("kriteria %q tidak dikenal", nama)
+	}
+	return nil
+}
+
+// SkorKriteriaPegawai menghitung skor pegawai (skala 0-5, makin tinggi makin baik) untuk setiap kriteria:
+// - KPI          => total KPI (Score * Weight / 100)
+// - Kondite      => 5 dikurangi total pengurang poin
+// - PenambahPoin => total penambah poin
+// - lainnya      => rata-rata skor pada tabel evaluations untuk kriteria tersebut (pada periode, jika diisi)
+func SkorKriteriaPegawai(empID uint, periode *models.EvaluationPeriod, totalKPI, pengurang, penambah float64, kriteria []string) (map[string]float64, error) {
+	skor := make(map[string]float64, len(kriteria))
+	for _, nama := range kriteria {
+		switch kunciKriteria(nama) {
+		case KriteriaKPI:
+			skor[nama] = batasiSkor(totalKPI)
+		case KriteriaKondite:
+			skor[nama] = batasiSkor(SkorMaksimal - pengurang)
+		case KriteriaPenambahPoin:
+			skor[nama] = batasiSkor(penambah)
+		default:
+			var rataRata float64
+			query := db.Model(&models.Evaluation{}).
+				Joins("JOIN criterions ON criterions.id = evaluations.criterion_id AND criterions.deleted_at IS NULL").
+				Where("evaluations.employee_id = ? AND criterions.name = ?", empID, nama)
+			if periode != nil {
+				query = query.Where("evaluations.period_id = ?", periode.ID)
+			}
+			err := query.Select("COALESCE(AVG(evaluations.score), 0)").Scan(&rataRata).Error
+			if err != nil {
+				return nil, err
+			}
+			skor[nama] = batasiSkor(rataRata)
+		}
+	}
+	return skor, nil
+}
+
+// SkorTerbobot => sum(bobot_i * skor_i)
+func SkorTerbobot(bobot, skor map[string]float64) float64 {
+	var total float64
+	for nama, w := range bobot {
+		total += w * skor[nama]
+	}
+	return total
+}
+
+// namaKriteria => daftar nama kriteria dari map bobot
+func namaKriteria(bobot map[string]float64) []string {
+	nama := make([]string, 0, len(bobot))
+	for n := range bobot {
+		nama = append(nama, n)
+	}
+	return nama
+}
+
+// batasiSkor => membatasi skor pada rentang 0 s.d. SkorMaksimal
+func batasiSkor(v float64) float64 {
+	return math.Min(math.Max(v, 0), SkorMaksimal)
 }
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BonusPoolRequest adalah payload alokasi bonus berdasarkan anggaran (pool)
+type BonusPoolRequest struct {
+	PeriodID        uint             `json:"period_id"`
+	TotalBudget     int64            `json:"total_budget" binding:"required"`
+	DepartmentPools map[string]int64 `json:"department_pools"` // opsional: sub-pool per departemen
+}
+
+// AlokasiPool => bonus hasil alokasi pool untuk satu pegawai
+type AlokasiPool struct {
+	EmployeeID          uint    `json:"employee_id"`
+	Name                string  `json:"name"`
+	Department          string  `json:"department"`
+	Pool                string  `json:"pool"` // nama departemen untuk sub-pool, "company" untuk pool umum; kosong jika tidak eligible
+	KPISetelahKalibrasi float64 `json:"kpi_setelah_kalibrasi"`
+	Gaji                float64 `json:"gaji"`
+	FaktorProrata       float64 `json:"faktor_prorata"`
+	Bobot               float64 `json:"bobot"` // KPI setelah kalibrasi * gaji * faktor prorata
+	Bonus               int64   `json:"bonus"`
+	AturanGagal         string  `json:"aturan_gagal,omitempty"`
+}
+
+// RingkasanPool => total anggaran & hasil alokasi satu pool
+type RingkasanPool struct {
+	Pool        string `json:"pool"`
+	Budget      int64  `json:"budget"`
+	Allocated   int64  `json:"allocated"`
+	JumlahOrang int    `json:"jumlah_orang"`
+}
+
+// PoolUmum => nama pool untuk pegawai yang departemennya tidak memiliki sub-pool
+const PoolUmum = "company"
+
+// AllocateBonusPool - POST /api/kalibrasi/pool
+// Mengalokasikan anggaran bonus periode secara proporsional terhadap KPI setelah kalibrasi x gaji x prorata.
+// Sub-pool departemen (opsional) dibagi ke pegawai departemen tersebut, sisanya ke pegawai lain.
+func AllocateBonusPool(c *gin.Context) {
+	var req BonusPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periode, ok := ambilPeriode(c, req.PeriodID)
+	if !ok {
+		return
+	}
+
+	hasil, err := HitungKalibrasi(OpsiKalibrasi{Periode: periode})
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+
+	alokasi, ringkasan, err := AlokasikanPool(hasil.Rows, req.TotalBudget, req.DepartmentPools)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alokasi, "pools": ringkasan, "periode": periode})
+}
+
+// AlokasikanPool membagi anggaran ke setiap baris kalibrasi secara proporsional (bobot = KPI x gaji x prorata)
+// dengan metode sisa terbesar, sehingga total alokasi setiap pool tepat sama dengan anggarannya.
+func AlokasikanPool(rows []KalibrasiResponse, total int64, deptPools map[string]int64) ([]AlokasiPool, []RingkasanPool, error) {
+	if total < 0 {
+		return nil, nil, fmt.Errorf("total_budget tidak boleh negatif")
+	}
+
+	// Anggaran pool umum = total dikurangi seluruh sub-pool departemen
+	budget := map[string]int64{PoolUmum: total}
+	for dept, nilai := range deptPools {
+		if dept == PoolUmum {
+			return nil, nil, fmt.Errorf("nama departemen %s dipakai untuk pool umum dan tidak boleh menjadi sub-pool", PoolUmum)
+		}
+		if nilai < 0 {
+			return nil, nil, fmt.Errorf("sub-pool departemen %s tidak boleh negatif", dept)
+		}
+		budget[dept] = nilai
+		budget[PoolUmum] -= nilai
+	}
+	if budget[PoolUmum] < 0 {
+		return nil, nil, fmt.Errorf("total sub-pool departemen melebihi total_budget")
+	}
+
+	// Kelompokkan pegawai per pool (pegawai yang tidak eligible tidak ikut pembagian)
+	anggota := map[string][]int{}
+	alokasi := make([]AlokasiPool, len(rows))
+	for i, r := range rows {
+		pool := PoolUmum
+		if _, ada := deptPools[r.Department]; ada {
+			pool = r.Department
+		}
+		if !r.Eligible {
+			pool = ""
+		}
+		alokasi[i] = AlokasiPool{
+			EmployeeID:          r.EmployeeID,
+			Name:                r.Name,
+			Department:          r.Department,
+			Pool:                pool,
+			KPISetelahKalibrasi: r.KPISetelahKalibrasi,
+			Gaji:                r.Gaji,
+			FaktorProrata:       r.FaktorProrata,
+			Bobot:               r.mentah.KPISetelahKalibrasi * r.Gaji * r.FaktorProrata, // KPI sebelum pembulatan
+			AturanGagal:         r.AturanGagal,
+		}
+		if pool != "" {
+			anggota[pool] = append(anggota[pool], i)
+		}
+	}
+
+	namaPool := make([]string, 0, len(budget))
+	for pool := range budget {
+		namaPool = append(namaPool, pool)
+	}
+	sort.Strings(namaPool)
+
+	ringkasan := []RingkasanPool{}
+	for _, pool := range namaPool {
+		idx := anggota[pool]
+		if len(idx) == 0 {
+			if budget[pool] > 0 {
+				return nil, nil, fmt.Errorf("pool %s memiliki anggaran tetapi tidak memiliki pegawai", pool)
+			}
+			continue
+		}
+
+		bobot := make([]float64, len(idx))
+		var totalBobot float64
+		for j, i := range idx {
+			bobot[j] = alokasi[i].Bobot
+			totalBobot += math.Max(bobot[j], 0)
+		}
+		// Tanpa bobot, anggaran akan dibagi rata ke pegawai dengan KPI 0; tolak
+		if totalBobot == 0 && budget[pool] > 0 {
+			return nil, nil, fmt.Errorf("pool %s memiliki anggaran tetapi seluruh bobot pegawainya 0", pool)
+		}
+		bagian := AlokasiSisaTerbesar(budget[pool], bobot)
+
+		var allocated int64
+		for j, i := range idx {
+			alokasi[i].Bonus = bagian[j]
+			allocated += bagian[j]
+		}
+		ringkasan = append(ringkasan, RingkasanPool{Pool: pool, Budget: budget[pool], Allocated: allocated, JumlahOrang: len(idx)})
+	}
+
+	return alokasi, ringkasan, nil
+}
+
+// AlokasiSisaTerbesar membagi total (bilangan bulat) sesuai proporsi bobot dengan metode
+// largest remainder: setiap bagian dibulatkan ke bawah, sisa dibagikan satu per satu ke bagian
+// dengan pecahan terbesar. Jika seluruh bobot 0 (atau negatif), total dibagi rata.
+func AlokasiSisaTerbesar(total int64, bobot []float64) []int64 {
+	hasil := make([]int64, len(bobot))
+	if len(bobot) == 0 {
+		return hasil
+	}
+
+	var totalBobot float64
+	for _, b := range bobot {
+		totalBobot += math.Max(b, 0)
+	}
+
+	pecahan := make([]float64, len(bobot))
+	var terbagi int64
+	for i := range bobot {
+		b := math.Max(bobot[i], 0)
+		ideal := float64(total) / float64(len(bobot))
+		if totalBobot > 0 {
+			ideal = float64(total) * b / totalBobot
+		}
+		hasil[i] = int64(math.Floor(ideal))
+		pecahan[i] = ideal - float64(hasil[i])
+		terbagi += hasil[i]
+	}
+
+	// Urutkan berdasarkan pecahan terbesar (seri => urutan awal)
+	urutan := make([]int, len(bobot))
+	for i := range urutan {
+		urutan[i] = i
+	}
+	sort.SliceStable(urutan, func(a, b int) bool { return pecahan[urutan[a]] > pecahan[urutan[b]] })
+
+	for k := 0; terbagi < total; k++ {
+		hasil[urutan[k%len(urutan)]]++
+		terbagi++
+	}
+	return hasil
+}
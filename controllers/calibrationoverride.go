@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalibrationOverrideInput adalah payload untuk pembuatan / update override kalibrasi
+type CalibrationOverrideInput struct {
+	PeriodID   uint     `json:"period_id" binding:"required"`
+	EmployeeID uint     `json:"employee_id" binding:"required"`
+	Scale      string   `json:"scale"`
+	FinalKPI   *float64 `json:"final_kpi"`
+	Reason     string   `json:"reason" binding:"required"`
+}
+
+// InfoOverride => nilai hasil hitung beserta override yang diterapkan pada baris kalibrasi
+type InfoOverride struct {
+	OverrideID    uint     `json:"override_id"`
+	KPIDihitung   float64  `json:"kpi_dihitung"`
+	SkalaDihitung string   `json:"skala_dihitung"`
+	FinalKPI      *float64 `json:"final_kpi,omitempty"`
+	Scale         string   `json:"scale,omitempty"`
+	Reason        string   `json:"reason"`
+	CreatedBy     uint     `json:"created_by"`
+	ApprovedBy    uint     `json:"approved_by"`
+}
+
+// GetCalibrationOverrides - GET /api/calibration-overrides?period_id=
+func GetCalibrationOverrides(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	var overrides []models.CalibrationOverride
+	if err := filterPeriode(db.Preload("Employee"), periode).Find(&overrides).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data override kalibrasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": overrides})
+}
+
+// CreateCalibrationOverride - POST /api/calibration-overrides
+// Mengusulkan override (status pending). Satu pegawai hanya boleh memiliki satu override
+// (selain yang rejected) per periode.
+func CreateCalibrationOverride(c *gin.Context) {
+	var input CalibrationOverrideInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var override models.CalibrationOverride
+	if !isiOverrideKalibrasi(c, &override, input) {
+		return
+	}
+
+	if !validasiOverrideTunggal(c, input.PeriodID, input.EmployeeID, 0) {
+		return
+	}
+
+	override.CreatedBy = idPenggunaLogin(c)
+	if err := db.Create(&override).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat override kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// UpdateCalibrationOverride - PUT /api/calibration-overrides/:id
+// Override yang sudah approved tidak dapat diubah (409). Override yang diubah kembali berstatus
+// pending dengan pengubah sebagai pengusul (created_by).
+func UpdateCalibrationOverride(c *gin.Context) {
+	var override models.CalibrationOverride
+	if err := db.First(&override, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Override kalibrasi tidak ditemukan"})
+		return
+	}
+	if override.Status == models.OverrideStatusApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Override yang sudah approved tidak dapat diubah"})
+		return
+	}
+
+	var input CalibrationOverrideInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.PeriodID != override.PeriodID || input.EmployeeID != override.EmployeeID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_id dan employee_id override tidak dapat diubah"})
+		return
+	}
+	// Override rejected yang diajukan ulang tidak boleh menyaingi override lain yang masih berlaku
+	if override.Status == models.OverrideStatusRejected && !validasiOverrideTunggal(c, override.PeriodID, override.EmployeeID, override.ID) {
+		return
+	}
+	if !isiOverrideKalibrasi(c, &override, input) {
+		return
+	}
+
+	// Pengubah terakhir dianggap pengusul, sehingga tidak dapat menyetujui perubahannya sendiri
+	override.CreatedBy = idPenggunaLogin(c)
+	override.ApprovedBy = 0
+	override.ApprovedAt = nil
+	if err := db.Save(&override).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui override kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// DeleteCalibrationOverride - DELETE /api/calibration-overrides/:id
+// Override yang sudah approved tidak dapat dihapus.
+func DeleteCalibrationOverride(c *gin.Context) {
+	var override models.CalibrationOverride
+	if err := db.First(&override, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Override kalibrasi tidak ditemukan"})
+		return
+	}
+	if override.Status == models.OverrideStatusApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Override yang sudah approved tidak dapat dihapus"})
+		return
+	}
+	if !validasiPeriodeInput(c, override.PeriodID) {
+		return
+	}
+
+	if err := db.Delete(&override).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus override kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// DecideCalibrationOverride - POST /api/calibration-overrides/:id/decision (khusus HR / admin)
+// pending: approve => approved, reject => rejected. Pengusul tidak boleh menyetujui usulannya sendiri.
+func DecideCalibrationOverride(c *gin.Context) {
+	var override models.CalibrationOverride
+	if err := db.First(&override, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Override kalibrasi tidak ditemukan"})
+		return
+	}
+	if override.Status != models.OverrideStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Override berstatus " + override.Status + " tidak dapat diputuskan lagi"})
+		return
+	}
+	if !validasiPeriodeInput(c, override.PeriodID) {
+		return
+	}
+
+	penyetuju := idPenggunaLogin(c)
+	if penyetuju == override.CreatedBy {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Pengusul tidak dapat memutuskan override miliknya sendiri"})
+		return
+	}
+
+	var input struct {
+		Decision string `json:"decision" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch input.Decision {
+	case "approve":
+		override.Status = models.OverrideStatusApproved
+	case "reject":
+		override.Status = models.OverrideStatusRejected
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision harus approve atau reject"})
+		return
+	}
+	now := time.Now()
+	override.ApprovedBy = penyetuju
+	override.ApprovedAt = &now
+
+	if err := db.Save(&override).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan keputusan override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// isiOverrideKalibrasi memvalidasi input lalu mengisi field override (status kembali pending).
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiOverrideKalibrasi(c *gin.Context, override *models.CalibrationOverride, input CalibrationOverrideInput) bool {
+	if !validasiPeriodeInput(c, input.PeriodID) {
+		return false
+	}
+	periode, ok := ambilPeriode(c, input.PeriodID)
+	if !ok {
+		return false
+	}
+	if (input.Scale == "") == (input.FinalKPI == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Isi salah satu dari scale atau final_kpi"})
+		return false
+	}
+	if input.FinalKPI != nil && *input.FinalKPI < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "final_kpi tidak boleh negatif"})
+		return false
+	}
+	if input.Scale != "" {
+		_, bands, err := SkalaUntukPeriode(periode)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tabel skala"})
+			return false
+		}
+		if _, ok := bandBerdasarkanLabel(bands, input.Scale); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Skala " + input.Scale + " tidak ada pada tabel skala periode"})
+			return false
+		}
+	}
+
+	override.PeriodID = input.PeriodID
+	override.EmployeeID = input.EmployeeID
+	override.Scale = input.Scale
+	override.FinalKPI = input.FinalKPI
+	override.Reason = input.Reason
+	override.Status = models.OverrideStatusPending
+	return true
+}
+
+// validasiOverrideTunggal memastikan pegawai belum memiliki override lain (selain kecuali) yang
+// tidak berstatus rejected pada periode. Jika sudah ada, response 409 langsung dikirim dan mengembalikan false.
+func validasiOverrideTunggal(c *gin.Context, periodID, empID, kecuali uint) bool {
+	var jumlah int64
+	if err := db.Model(&models.CalibrationOverride{}).
+		Where("period_id = ? AND employee_id = ? AND status <> ? AND id <> ?", periodID, empID, models.OverrideStatusRejected, kecuali).
+		Count(&jumlah).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa override kalibrasi"})
+		return false
+	}
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pegawai sudah memiliki override pada periode ini, gunakan update"})
+		return false
+	}
+	return true
+}
+
+// overrideKalibrasiDisetujui => employee_id => override approved pada periode (kosong tanpa periode)
+func overrideKalibrasiDisetujui(periode *models.EvaluationPeriod) (map[uint]models.CalibrationOverride, error) {
+	hasil := map[uint]models.CalibrationOverride{}
+	if periode == nil {
+		return hasil, nil
+	}
+
+	var overrides []models.CalibrationOverride
+	if err := db.Where("period_id = ? AND status = ?", periode.ID, models.OverrideStatusApproved).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		hasil[o.EmployeeID] = o
+	}
+	return hasil, nil
+}
@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCalibrationSessions - GET /api/calibration-sessions?period_id=
+func GetCalibrationSessions(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	var sessions []models.CalibrationSession
+	if err := filterPeriode(db.Preload("Participants.Employee"), periode).Order("id desc").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data sesi kalibrasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// GetCalibrationSession - GET /api/calibration-sessions/:id
+// Mengembalikan sesi beserta peserta dan override yang dicatat pada sesi tersebut.
+func GetCalibrationSession(c *gin.Context) {
+	session, ok := ambilSesiKalibrasi(c)
+	if !ok {
+		return
+	}
+
+	var overrides []models.CalibrationOverride
+	if err := db.Preload("Employee").Where("session_id = ?", session.ID).Find(&overrides).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil override sesi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session, "overrides": overrides})
+}
+
+// OpenCalibrationSession - POST /api/calibration-sessions
+// Membuka sesi kalibrasi. Periode harus berstatus calibration.
+func OpenCalibrationSession(c *gin.Context) {
+	var input struct {
+		PeriodID       uint   `json:"period_id" binding:"required"`
+		Name           string `json:"name" binding:"required"`
+		Department     string `json:"department"`
+		ManagerID      uint   `json:"manager_id"`
+		ParticipantIDs []uint `json:"participant_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periode, ok := ambilPeriode(c, input.PeriodID)
+	if !ok {
+		return
+	}
+	if periode.Status != models.PeriodStatusCalibration {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sesi kalibrasi hanya dapat dibuka pada periode berstatus calibration"})
+		return
+	}
+
+	// Peserta yang disebut lebih dari sekali hanya dicatat sekali
+	var peserta []uint
+	sudahAda := map[uint]bool{}
+	for _, id := range input.ParticipantIDs {
+		if !sudahAda[id] {
+			sudahAda[id] = true
+			peserta = append(peserta, id)
+		}
+	}
+
+	var jumlah int64
+	if err := db.Model(&models.Employee{}).Where("id IN ?", peserta).Count(&jumlah).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa peserta sesi"})
+		return
+	}
+	if int(jumlah) != len(peserta) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sebagian peserta tidak ditemukan"})
+		return
+	}
+
+	session := models.CalibrationSession{
+		PeriodID:   input.PeriodID,
+		Name:       input.Name,
+		Department: input.Department,
+		ManagerID:  input.ManagerID,
+		Status:     models.SessionStatusOpen,
+		OpenedBy:   idPenggunaLogin(c),
+	}
+	for _, id := range peserta {
+		session.Participants = append(session.Participants, models.CalibrationSessionParticipant{EmployeeID: id})
+	}
+
+	if err := db.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka sesi kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// GetSessionKalibrasi - GET /api/calibration-sessions/:id/kalibrasi
+// Hasil kalibrasi periode sesi, hanya untuk pegawai dalam cakupan sesi.
+func GetSessionKalibrasi(c *gin.Context) {
+	session, ok := ambilSesiKalibrasi(c)
+	if !ok {
+		return
+	}
+	periode, ok := ambilPeriode(c, session.PeriodID)
+	if !ok {
+		return
+	}
+
+	rows, err := kalibrasiSesi(session, periode)
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    rows,
+		"session": session,
+		"periode": periode,
+	})
+}
+
+// RecordSessionOverride - POST /api/calibration-sessions/:id/overrides
+// Mencatat keputusan komite untuk satu pegawai dalam cakupan sesi. Jika pegawai sudah memiliki
+// override (selain rejected) pada periode, override tersebut diganti dan kembali menunggu persetujuan.
+func RecordSessionOverride(c *gin.Context) {
+	session, ok := ambilSesiKalibrasi(c)
+	if !ok {
+		return
+	}
+	if !validasiSesiTerbuka(c, session) {
+		return
+	}
+
+	var input struct {
+		EmployeeID uint     `json:"employee_id" binding:"required"`
+		Scale      string   `json:"scale"`
+		FinalKPI   *float64 `json:"final_kpi"`
+		Reason     string   `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cakupan, err := pegawaiDalamSesi(session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil cakupan sesi"})
+		return
+	}
+	if !cakupan[input.EmployeeID] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pegawai tidak termasuk dalam cakupan sesi"})
+		return
+	}
+
+	// Override yang masih berlaku (bukan rejected) diganti; jika tidak ada, buat override baru
+	var override models.CalibrationOverride
+	err = db.Where("period_id = ? AND employee_id = ? AND status <> ?", session.PeriodID, input.EmployeeID, models.OverrideStatusRejected).
+		Order("id desc").First(&override).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil override pegawai"})
+		return
+	}
+
+	if !isiOverrideKalibrasi(c, &override, CalibrationOverrideInput{
+		PeriodID:   session.PeriodID,
+		EmployeeID: input.EmployeeID,
+		Scale:      input.Scale,
+		FinalKPI:   input.FinalKPI,
+		Reason:     input.Reason,
+	}) {
+		return
+	}
+	override.SessionID = session.ID
+	override.CreatedBy = idPenggunaLogin(c)
+	override.ApprovedBy = 0
+	override.ApprovedAt = nil
+
+	if err := db.Omit("Employee").Save(&override).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan keputusan sesi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// CloseCalibrationSession - POST /api/calibration-sessions/:id/close
+// Menutup sesi dan menyusun notulen (peserta, cakupan, keputusan override & alasannya).
+func CloseCalibrationSession(c *gin.Context) {
+	session, ok := ambilSesiKalibrasi(c)
+	if !ok {
+		return
+	}
+	if !validasiSesiTerbuka(c, session) {
+		return
+	}
+
+	var input struct {
+		Note string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periode, ok := ambilPeriode(c, session.PeriodID)
+	if !ok {
+		return
+	}
+	rows, err := kalibrasiSesi(session, periode)
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+	var overrides []models.CalibrationOverride
+	if err := db.Where("session_id = ?", session.ID).Order("employee_id asc").Find(&overrides).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil override sesi"})
+		return
+	}
+
+	now := time.Now()
+	session.Status = models.SessionStatusClosed
+	session.ClosedBy = idPenggunaLogin(c)
+	session.ClosedAt = &now
+	session.Minutes = susunNotulen(session, periode, rows, overrides, input.Note)
+
+	if err := db.Omit("Participants").Save(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menutup sesi kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// ambilSesiKalibrasi mengambil sesi dari :id beserta pesertanya.
+// Jika tidak ditemukan, response error langsung dikirim dan ok = false.
+func ambilSesiKalibrasi(c *gin.Context) (models.CalibrationSession, bool) {
+	var session models.CalibrationSession
+	if err := db.Preload("Participants.Employee").First(&session, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi kalibrasi tidak ditemukan"})
+		return session, false
+	}
+	return session, true
+}
+
+// validasiSesiTerbuka memastikan sesi masih open, periodenya belum terkunci, dan pengguna login
+// adalah peserta / pembuka sesi (atau admin). Jika tidak, response error langsung dikirim.
+func validasiSesiTerbuka(c *gin.Context, session models.CalibrationSession) bool {
+	if session.Status != models.SessionStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sesi kalibrasi sudah ditutup"})
+		return false
+	}
+	if !validasiPeriodeInput(c, session.PeriodID) {
+		return false
+	}
+
+	pengguna := idPenggunaLogin(c)
+	if role, _ := c.Get("role"); role == models.RoleAdmin || pengguna == session.OpenedBy {
+		return true
+	}
+	for _, p := range session.Participants {
+		if p.EmployeeID == pengguna {
+			return true
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Hanya peserta sesi yang dapat mengubah sesi kalibrasi"})
+	return false
+}
+
+// pegawaiDalamSesi => employee_id pegawai dalam cakupan sesi (departemen dan/atau atasan langsung)
+func pegawaiDalamSesi(session models.CalibrationSession) (map[uint]bool, error) {
+	query := db.Model(&models.Employee{})
+	if session.Department != "" {
+		query = query.Where("department = ?", session.Department)
+	}
+	if session.ManagerID != 0 {
+		query = query.Where("manager_id = ?", session.ManagerID)
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	hasil := map[uint]bool{}
+	for _, id := range ids {
+		hasil[id] = true
+	}
+	return hasil, nil
+}
+
+// kalibrasiSesi => baris kalibrasi periode sesi yang termasuk cakupan sesi
+func kalibrasiSesi(session models.CalibrationSession, periode *models.EvaluationPeriod) ([]KalibrasiResponse, error) {
+	cakupan, err := pegawaiDalamSesi(session)
+	if err != nil {
+		return nil, err
+	}
+	hasil, err := HitungKalibrasi(OpsiKalibrasi{Periode: periode})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []KalibrasiResponse{}
+	for _, row := range hasil.Rows {
+		if cakupan[row.EmployeeID] {
+			row.No = len(rows) + 1
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// susunNotulen menyusun notulen sesi dalam bentuk teks
+func susunNotulen(session models.CalibrationSession, periode *models.EvaluationPeriod, rows []KalibrasiResponse, overrides []models.CalibrationOverride, catatan string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Notulen Sesi Kalibrasi: %s\n", session.Name)
+	fmt.Fprintf(&sb, "Periode: %s\n", periode.Name)
+
+	var cakupan []string
+	if session.Department != "" {
+		cakupan = append(cakupan, "departemen "+session.Department)
+	}
+	if session.ManagerID != 0 {
+		cakupan = append(cakupan, fmt.Sprintf("bawahan langsung pegawai #%d", session.ManagerID))
+	}
+	if len(cakupan) == 0 {
+		cakupan = append(cakupan, "seluruh pegawai")
+	}
+	fmt.Fprintf(&sb, "Cakupan: %s\n", strings.Join(cakupan, ", "))
+	fmt.Fprintf(&sb, "Ditutup: %s oleh pegawai #%d\n", session.ClosedAt.Format("2006-01-02 15:04"), session.ClosedBy)
+
+	var peserta []string
+	for _, p := range session.Participants {
+		peserta = append(peserta, p.Employee.Name)
+	}
+	fmt.Fprintf(&sb, "Peserta: %s\n", strings.Join(peserta, ", "))
+	fmt.Fprintf(&sb, "Jumlah pegawai ditinjau: %d\n", len(rows))
+
+	barisPegawai := map[uint]KalibrasiResponse{}
+	for _, row := range rows {
+		barisPegawai[row.EmployeeID] = row
+	}
+
+	fmt.Fprintf(&sb, "\nKeputusan (%d):\n", len(overrides))
+	for _, o := range overrides {
+		row := barisPegawai[o.EmployeeID]
+		kpi, skala := row.KPISetelahKalibrasi, row.Skala
+		if row.Override != nil {
+			kpi, skala = row.Override.KPIDihitung, row.Override.SkalaDihitung
+		}
+
+		keputusan := o.Scale
+		if o.FinalKPI != nil {
+			keputusan = fmt.Sprintf("KPI %.1f", *o.FinalKPI)
+		}
+		fmt.Fprintf(&sb, "- %s (#%d): KPI %.1f (%s) => %s; alasan: %s [%s]\n", row.Name, o.EmployeeID, kpi, skala, keputusan, o.Reason, o.Status)
+	}
+
+	if catatan != "" {
+		fmt.Fprintf(&sb, "\nCatatan: %s\n", catatan)
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CriterionInput adalah payload untuk pembuatan / update kriteria penilaian
+type CriterionInput struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"` // 0..1, total seluruh kriteria harus 1
+}
+
+// GetCriteria - GET /api/criteria
+// Mengembalikan daftar kriteria beserta total bobotnya
+func GetCriteria(c *gin.Context) {
+	var criteria []models.Criterion
+	if err := db.Find(&criteria).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kriteria"})
+		return
+	}
+
+	var total float64
+	for _, k := range criteria {
+		total += k.Weight
+	}
+	c.JSON(http.StatusOK, gin.H{"data": criteria, "total_weight": RoundFloat(total, 4)})
+}
+
+// CreateCriterion - POST /api/criteria
+func CreateCriterion(c *gin.Context) {
+	var input CriterionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Weight < 0 || input.Weight > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bobot kriteria harus di antara 0 dan 1"})
+		return
+	}
+
+	if !validasiNamaKriteria(c, input.Name, 0) {
+		return
+	}
+
+	criterion := models.Criterion{
+		Name:        input.Name,
+		Description: input.Description,
+		Weight:      input.Weight,
+	}
+	if err := db.Create(&criterion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kriteria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": criterion})
+}
+
+// UpdateCriterion - PUT /api/criteria/:id
+func UpdateCriterion(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var criterion models.Criterion
+	if err := db.First(&criterion, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kriteria tidak ditemukan"})
+		return
+	}
+
+	var input CriterionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Weight < 0 || input.Weight > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bobot kriteria harus di antara 0 dan 1"})
+		return
+	}
+
+	if !validasiNamaKriteria(c, input.Name, criterion.ID) {
+		return
+	}
+
+	criterion.Name = input.Name
+	criterion.Description = input.Description
+	criterion.Weight = input.Weight
+
+	if err := db.Save(&criterion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kriteria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": criterion})
+}
+
+// DeleteCriterion - DELETE /api/criteria/:id
+func DeleteCriterion(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var criterion models.Criterion
+	if err := db.First(&criterion, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kriteria tidak ditemukan"})
+		return
+	}
+
+	if err := db.Delete(&criterion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kriteria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// validasiNamaKriteria memastikan nama kriteria belum dipakai kriteria lain (selain kecuali).
+// Jika sudah dipakai, response 400 langsung dikirim dan mengembalikan false.
+func validasiNamaKriteria(c *gin.Context, nama string, kecuali uint) bool {
+	var jumlah int64
+	if err := db.Model(&models.Criterion{}).Where("name = ? AND id <> ?", nama, kecuali).Count(&jumlah).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa nama kriteria"})
+		return false
+	}
+	if jumlah > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama kriteria " + nama + " sudah dipakai"})
+		return false
+	}
+	return true
+}
+
+// BobotKriteriaTersimpan mengambil bobot dari tabel kriteria (nama => bobot).
+// Mengembalikan map kosong jika belum ada kriteria, dan error jika total bobot tidak sama dengan 1.
+func BobotKriteriaTersimpan() (map[string]float64, error) {
+	var criteria []models.Criterion
+	if err := db.Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+
+	bobot := make(map[string]float64, len(criteria))
+	for _, k := range criteria {
+		bobot[k.Name] = k.Weight
+	}
+	if err := validasiTotalBobot(bobot); err != nil {
+		return nil, err
+	}
+	return bobot, nil
+}
+
+// validasiTotalBobot => error ErrBobotKriteria jika bobot tidak kosong dan totalnya tidak sama dengan 1
+func validasiTotalBobot(bobot map[string]float64) error {
+	var total float64
+	for _, w := range bobot {
+		total += w
+	}
+	if len(bobot) > 0 && math.Abs(total-1) > 0.001 {
+		return fmt.Errorf("%w, saat ini %.4f", ErrBobotKriteria, total)
+	}
+	return nil
+}
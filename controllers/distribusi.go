@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bonus/models"
+)
+
+// Mode & cakupan kalibrasi distribusi paksa (forced distribution)
+const (
+	ModeKalibrasiAmbang = "threshold" // skala mengikuti ambang band (default)
+	ModeKalibrasiPaksa  = "forced"    // skala mengikuti kuota distribusi
+
+	CakupanPerusahaan = "company"
+	CakupanDepartemen = "department"
+)
+
+// TargetDistribusiDefault => pedoman manajemen (persen) untuk label SkalaDefault
+var TargetDistribusiDefault = map[string]float64{
+	"Exceptional": 10,
+	"Outstanding": 20,
+	"Good":        40,
+	"Fair":        20,
+	"Poor":        10,
+}
+
+// UrutanTieBreak => urutan aturan penentu peringkat jika KPI setelah kalibrasi sama
+var UrutanTieBreak = []string{
+	"kpi_setelah_kalibrasi desc",
+	"total_kpi desc",
+	"pengurang_poin asc",
+	"employee_id asc",
+}
+
+// DeviasiDistribusi => perbandingan distribusi alami (berdasarkan ambang) dengan target untuk satu band
+type DeviasiDistribusi struct {
+	Skala         string  `json:"skala"`
+	TargetPersen  float64 `json:"target_persen"`
+	TargetJumlah  int64   `json:"target_jumlah"`
+	AlamiJumlah   int     `json:"alami_jumlah"`
+	AlamiPersen   float64 `json:"alami_persen"`
+	SelisihJumlah int     `json:"selisih_jumlah"` // alami - target
+}
+
+// LaporanDistribusi => hasil distribusi paksa untuk satu kelompok (departemen atau perusahaan)
+type LaporanDistribusi struct {
+	Kelompok      string              `json:"kelompok"`
+	JumlahDinilai int                 `json:"jumlah_dinilai"`
+	JumlahPindah  int                 `json:"jumlah_pindah"` // pegawai yang skalanya berubah dari skala alami
+	Deviasi       []DeviasiDistribusi `json:"deviasi"`
+}
+
+// ParseTargetDistribusi membaca target "Exceptional:10,Outstanding:20,..." (persen, total 100).
+// String kosong => TargetDistribusiDefault. Setiap label harus ada pada bands.
+func ParseTargetDistribusi(s string, bands []models.ScaleBand) (map[string]float64, error) {
+	target := TargetDistribusiDefault
+	if s != "" {
+		target = map[string]float64{}
+		for _, bagian := range strings.Split(s, ",") {
+			label, nilai, ok := strings.Cut(bagian, ":")
+			if !ok {
+				return nil, fmt.Errorf("format target %q tidak valid, gunakan Label:persen", bagian)
+			}
+			persen, err := strconv.ParseFloat(strings.TrimSpace(nilai), 64)
+			if err != nil || persen < 0 {
+				return nil, fmt.Errorf("persentase target %q tidak valid", bagian)
+			}
+			target[strings.TrimSpace(label)] = persen
+		}
+	}
+
+	var total float64
+	for label, persen := range target {
+		if _, ok := bandBerdasarkanLabel(bands, label); !ok {
+			return nil, fmt.Errorf("label skala %s tidak ada pada tabel skala", label)
+		}
+		total += persen
+	}
+	if math.Abs(total-100) > 1e-6 {
+		return nil, fmt.Errorf("total persentase target harus 100, saat ini %.2f", total)
+	}
+	return target, nil
+}
+
+// TerapkanDistribusiPaksa menentukan ulang Skala, Multiplier & Bonus berdasarkan kuota target.
+// Pegawai eligible diurutkan per kelompok (UrutanTieBreak), lalu kuota tiap band (dihitung dengan
+// AlokasiSisaTerbesar) diisi mulai dari band tertinggi. Pegawai tidak eligible maupun yang memiliki
+// override komite tidak ikut diperingkat dan tetap memakai skala alami / skala override.
+// Bands harus terurut berdasarkan LowerBound.
+func TerapkanDistribusiPaksa(rows []KalibrasiResponse, bands []models.ScaleBand, target map[string]float64, cakupan string) []LaporanDistribusi {
+	// Kelompokkan indeks baris eligible
+	kelompok := map[string][]int{}
+	var namaKelompok []string
+	for i := range rows {
+		rows[i].SkalaAlami = rows[i].Skala
+		if !rows[i].Eligible || rows[i].Override != nil {
+			continue
+		}
+		nama := CakupanPerusahaan
+		if cakupan == CakupanDepartemen {
+			nama = rows[i].Department
+		}
+		if _, ok := kelompok[nama]; !ok {
+			namaKelompok = append(namaKelompok, nama)
+		}
+		kelompok[nama] = append(kelompok[nama], i)
+	}
+	sort.Strings(namaKelompok)
+
+	// Band dari tertinggi ke terendah beserta persentase targetnya
+	urutan := make([]models.ScaleBand, len(bands))
+	persen := make([]float64, len(bands))
+	for i, band := range bands {
+		urutan[len(bands)-1-i] = band
+		persen[len(bands)-1-i] = target[band.Label]
+	}
+
+	laporan := []LaporanDistribusi{}
+	for _, nama := range namaKelompok {
+		indeks := kelompok[nama]
+		sort.SliceStable(indeks, func(a, b int) bool { return peringkatLebihTinggi(rows[indeks[a]], rows[indeks[b]]) })
+
+		kuota := AlokasiSisaTerbesar(int64(len(indeks)), persen)
+		alami := map[string]int{}
+		pindah := 0
+		posisi := 0
+		for b, band := range urutan {
+			for n := int64(0); n < kuota[b]; n++ {
+				row := &rows[indeks[posisi]]
+				alami[row.SkalaAlami]++
+				if row.SkalaAlami != band.Label {
+					pindah++
+				}
+				row.Skala = band.Label
+				row.Multiplier = band.Multiplier
+				row.Bonus = HitungBonusBand(band, row.Gaji, band.Multiplier) * row.FaktorProrata
+				posisi++
+			}
+		}
+
+		item := LaporanDistribusi{Kelompok: nama, JumlahDinilai: len(indeks), JumlahPindah: pindah, Deviasi: []DeviasiDistribusi{}}
+		for b, band := range urutan {
+			item.Deviasi = append(item.Deviasi, DeviasiDistribusi{
+				Skala:         band.Label,
+				TargetPersen:  persen[b],
+				TargetJumlah:  kuota[b],
+				AlamiJumlah:   alami[band.Label],
+				AlamiPersen:   RoundFloat(float64(alami[band.Label])*100/float64(len(indeks)), 2),
+				SelisihJumlah: alami[band.Label] - int(kuota[b]),
+			})
+		}
+		laporan = append(laporan, item)
+	}
+	return laporan
+}
+
+// peringkatLebihTinggi => true jika a berada di atas b menurut UrutanTieBreak.
+// Dibandingkan pada nilai sebelum pembulatan agar selisih kecil tidak dianggap seri.
+func peringkatLebihTinggi(a, b KalibrasiResponse) bool {
+	if a.mentah.KPISetelahKalibrasi != b.mentah.KPISetelahKalibrasi {
+		return a.mentah.KPISetelahKalibrasi > b.mentah.KPISetelahKalibrasi
+	}
+	if a.mentah.TotalKPI != b.mentah.TotalKPI {
+		return a.mentah.TotalKPI > b.mentah.TotalKPI
+	}
+	if a.mentah.PengurangPoin != b.mentah.PengurangPoin {
+		return a.mentah.PengurangPoin < b.mentah.PengurangPoin
+	}
+	return a.EmployeeID < b.EmployeeID
+}
+
+// bandBerdasarkanLabel => band dengan label tertentu (ok = false jika tidak ada)
+func bandBerdasarkanLabel(bands []models.ScaleBand, label string) (models.ScaleBand, bool) {
+	for _, band := range bands {
+		if band.Label == label {
+			return band, true
+		}
+	}
+	return models.ScaleBand{}, false
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EligibilityRuleInput adalah payload untuk pembuatan / update aturan eligibilitas
+type EligibilityRuleInput struct {
+	Name        string `json:"name" binding:"required"`
+	Type        string `json:"type" binding:"required"`
+	Value       string `json:"value"`
+	Active      *bool  `json:"active"` // default true
+	Description string `json:"description"`
+}
+
+// GetEligibilityRules - GET /api/eligibility-rules
+func GetEligibilityRules(c *gin.Context) {
+	var rules []models.EligibilityRule
+	if err := db.Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data aturan eligibilitas"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// CreateEligibilityRule - POST /api/eligibility-rules
+func CreateEligibilityRule(c *gin.Context) {
+	var input EligibilityRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.EligibilityRule{Active: true}
+	if !isiAturanEligibilitas(c, &rule, input) {
+		return
+	}
+
+	if err := db.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat aturan eligibilitas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// UpdateEligibilityRule - PUT /api/eligibility-rules/:id
+func UpdateEligibilityRule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var rule models.EligibilityRule
+	if err := db.First(&rule, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Aturan eligibilitas tidak ditemukan"})
+		return
+	}
+
+	var input EligibilityRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isiAturanEligibilitas(c, &rule, input) {
+		return
+	}
+
+	if err := db.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui aturan eligibilitas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// DeleteEligibilityRule - DELETE /api/eligibility-rules/:id
+func DeleteEligibilityRule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var rule models.EligibilityRule
+	if err := db.First(&rule, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Aturan eligibilitas tidak ditemukan"})
+		return
+	}
+
+	if err := db.Delete(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus aturan eligibilitas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// isiAturanEligibilitas memvalidasi input lalu mengisi field aturan.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiAturanEligibilitas(c *gin.Context, rule *models.EligibilityRule, input EligibilityRuleInput) bool {
+	switch input.Type {
+	case models.RuleTypeRole, models.RuleTypeKonditeCategory, models.RuleTypeEmploymentStatus:
+		if strings.TrimSpace(input.Value) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "value wajib diisi untuk aturan " + input.Type})
+			return false
+		}
+	case models.RuleTypeTenure, models.RuleTypeMinFinalKPI:
+		if _, err := strconv.ParseFloat(input.Value, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "value harus berupa angka untuk aturan " + input.Type})
+			return false
+		}
+	case models.RuleTypeResignedBeforePayout:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("type harus salah satu dari: %s, %s, %s, %s, %s, %s",
+			models.RuleTypeRole, models.RuleTypeTenure, models.RuleTypeKonditeCategory,
+			models.RuleTypeEmploymentStatus, models.RuleTypeResignedBeforePayout, models.RuleTypeMinFinalKPI)})
+		return false
+	}
+
+	rule.Name = input.Name
+	rule.Type = input.Type
+	rule.Value = input.Value
+	rule.Description = input.Description
+	if input.Active != nil {
+		rule.Active = *input.Active
+	}
+	return true
+}
+
+// aturanEligibilitasAktif => seluruh aturan eligibilitas yang aktif
+func aturanEligibilitasAktif() ([]models.EligibilityRule, error) {
+	var rules []models.EligibilityRule
+	if err := db.Where("active = ?", true).Order("id asc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// EvaluasiEligibilitas memeriksa setiap aturan terhadap pegawai. Mengembalikan false beserta
+// nama aturan pertama yang tidak terpenuhi, atau true jika seluruh aturan terpenuhi.
+func EvaluasiEligibilitas(rules []models.EligibilityRule, emp models.Employee, periode *models.EvaluationPeriod,
+	finalKPI float64, kondites []models.Kondite) (bool, string) {
+	for _, rule := range rules {
+		if !aturanTerpenuhi(rule, emp, periode, finalKPI, kondites) {
+			return false, rule.Name
+		}
+	}
+	return true, ""
+}
+
+// aturanTerpenuhi => true jika pegawai memenuhi satu aturan eligibilitas
+func aturanTerpenuhi(rule models.EligibilityRule, emp models.Employee, periode *models.EvaluationPeriod,
+	finalKPI float64, kondites []models.Kondite) bool {
+	switch rule.Type {
+	case models.RuleTypeRole:
+		return !dalamDaftar(rule.Value, emp.Role)
+
+	case models.RuleTypeEmploymentStatus:
+		return !dalamDaftar(rule.Value, emp.EmploymentStatus)
+
+	case models.RuleTypeKonditeCategory:
+		for _, k := range kondites {
+			if dalamDaftar(rule.Value, k.Category) {
+				return false
+			}
+		}
+		return true
+
+	case models.RuleTypeTenure:
+		// Masa kerja tidak diketahui / tanpa periode (tidak ada tanggal acuan) => aturan dianggap terpenuhi
+		if emp.HireDate == nil || periode == nil {
+			return true
+		}
+		minBulan, _ := strconv.ParseFloat(rule.Value, 64)
+		return float64(selisihBulan(*emp.HireDate, periode.EndDate)) >= minBulan
+
+	case models.RuleTypeResignedBeforePayout:
+		if emp.TerminationDate == nil || periode == nil {
+			return true
+		}
+		// payout_date belum diisi => acuan akhir periode, agar hasil tidak bergantung tanggal request
+		tanggalBayar := periode.EndDate
+		if periode.PayoutDate != nil {
+			tanggalBayar = *periode.PayoutDate
+		}
+		return !tanggalSaja(*emp.TerminationDate).Before(tanggalSaja(tanggalBayar))
+
+	case models.RuleTypeMinFinalKPI:
+		minKPI, _ := strconv.ParseFloat(rule.Value, 64)
+		return finalKPI >= minKPI
+	}
+	return true
+}
+
+// dalamDaftar => true jika nilai ada pada daftar dipisah koma (tidak peka huruf besar/kecil)
+func dalamDaftar(daftar, nilai string) bool {
+	for _, item := range strings.Split(daftar, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(nilai)) {
+			return true
+		}
+	}
+	return false
+}
+
+// selisihBulan => jumlah bulan penuh dari a s.d. b
+func selisihBulan(a, b time.Time) int {
+	bulan := (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+	if b.Day() < a.Day() {
+		bulan--
+	}
+	return bulan
+}
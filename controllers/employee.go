@@ -3,6 +3,8 @@ package controllers
 import (
 	"bonus/models"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -26,6 +28,12 @@ func CreateEmployee(c *gin.Context) {
 		Password string `json:"password" binding:"required"`
 		Role     string `json:"role" binding:"required"`
 		Salary   int    `json:"salary" binding:"required"` // Field gaji
+
+		Department       string `json:"department"`
+		ManagerID        *uint  `json:"manager_id"`
+		EmploymentStatus string `json:"employment_status"`
+		HireDate         string `json:"hire_date"`        // Format "YYYY-MM-DD"
+		TerminationDate  string `json:"termination_date"` // Format "YYYY-MM-DD"
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -33,6 +41,17 @@ func CreateEmployee(c *gin.Context) {
 		return
 	}
 
+	hireDate, err := parseTanggalOpsional(input.HireDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format hire_date tidak valid (YYYY-MM-DD)"})
+		return
+	}
+	terminationDate, err := parseTanggalOpsional(input.TerminationDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format termination_date tidak valid (YYYY-MM-DD)"})
+		return
+	}
+
 	// Hash password sebelum disimpan
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -46,6 +65,12 @@ func CreateEmployee(c *gin.Context) {
 		Password: string(hashedPassword),
 		Role:     input.Role,
 		Salary:   input.Salary, // Simpan salary
+
+		Department:       input.Department,
+		ManagerID:        input.ManagerID,
+		EmploymentStatus: input.EmploymentStatus,
+		HireDate:         hireDate,
+		TerminationDate:  terminationDate,
 	}
 
 	if err := db.Create(&employee).Error; err != nil {
@@ -71,16 +96,61 @@ func UpdateEmployee(c *gin.Context) {
 		Email  string `json:"email"`
 		Role   string `json:"role"`
 		Salary int    `json:"salary"`
+
+		Department       *string               `json:"department"` // tidak dikirim => data lama
+		ManagerID        *uint                 `json:"manager_id"`
+		EmploymentStatus string                `json:"employment_status"`
+		HireDate         string                `json:"hire_date"`        // Format "YYYY-MM-DD"
+		TerminationDate  inputOpsional[string] `json:"termination_date"` // Format "YYYY-MM-DD", null / "" => dikosongkan
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	hireLama, terminationLama := employee.HireDate, employee.TerminationDate
+
+	// Jika hire_date kosong / termination_date tidak dikirim, biarkan data lama
+	if input.HireDate != "" {
+		hireDate, err := parseTanggalOpsional(input.HireDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format hire_date tidak valid (YYYY-MM-DD)"})
+			return
+		}
+		employee.HireDate = hireDate
+	}
+	// termination_date yang dikirim null / kosong => pegawai kembali aktif (tanggal dihapus)
+	if input.TerminationDate.Ada {
+		var teks string
+		if input.TerminationDate.Nilai != nil {
+			teks = *input.TerminationDate.Nilai
+		}
+		terminationDate, err := parseTanggalOpsional(teks)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format termination_date tidak valid (YYYY-MM-DD)"})
+			return
+		}
+		employee.TerminationDate = terminationDate
+	}
+
+	// Tanggal masuk / keluar memengaruhi prorata periode di antara tanggal lama dan baru
+	if !validasiPerubahanTanggal(c, hireLama, employee.HireDate, tanggalPalingAwal) ||
+		!validasiPerubahanTanggal(c, terminationLama, employee.TerminationDate, tanggalPalingAkhir) {
+		return
+	}
 
 	employee.Name = input.Name
 	employee.Email = input.Email
 	employee.Role = input.Role
 	employee.Salary = input.Salary // Update gaji
+	if input.Department != nil {
+		employee.Department = *input.Department
+	}
+	if input.ManagerID != nil {
+		employee.ManagerID = input.ManagerID
+	}
+	if input.EmploymentStatus != "" {
+		employee.EmploymentStatus = input.EmploymentStatus
+	}
 
 	if err := db.Save(&employee).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui data pegawai"})
@@ -107,3 +177,127 @@ func DeleteEmployee(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": "Pegawai berhasil dihapus"})
 }
+
+// GetEmployeeLeaves - GET /api/employees/:id/leaves
+// Mengambil daftar cuti pegawai.
+func GetEmployeeLeaves(c *gin.Context) {
+	var leaves []models.EmployeeLeave
+	if err := db.Where("employee_id = ?", c.Param("id")).Order("start_date asc").Find(&leaves).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data cuti"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": leaves})
+}
+
+// CreateEmployeeLeave - POST /api/employees/:id/leaves
+// Mencatat interval cuti pegawai (unpaid = cuti tidak dibayar, mengurangi prorata bonus).
+func CreateEmployeeLeave(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var employee models.Employee
+	if err := db.First(&employee, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pegawai tidak ditemukan"})
+		return
+	}
+
+	var input struct {
+		StartDate   string `json:"start_date" binding:"required"` // Format "YYYY-MM-DD"
+		EndDate     string `json:"end_date" binding:"required"`   // Format "YYYY-MM-DD"
+		Unpaid      bool   `json:"unpaid"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format start_date tidak valid (YYYY-MM-DD)"})
+		return
+	}
+	end, err := time.Parse("2006-01-02", input.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format end_date tidak valid (YYYY-MM-DD)"})
+		return
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date tidak boleh sebelum start_date"})
+		return
+	}
+	// Cuti memengaruhi prorata setiap periode yang overlap, periode terkunci tidak boleh berubah
+	if !validasiRentangTidakTerkunci(c, start, end) {
+		return
+	}
+
+	leave := models.EmployeeLeave{
+		EmployeeID:  employee.ID,
+		StartDate:   start,
+		EndDate:     end,
+		Unpaid:      input.Unpaid,
+		Description: input.Description,
+	}
+	if err := db.Create(&leave).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data cuti"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": leave})
+}
+
+// DeleteEmployeeLeave - DELETE /api/employee-leaves/:id
+func DeleteEmployeeLeave(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var leave models.EmployeeLeave
+	if err := db.First(&leave, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data cuti tidak ditemukan"})
+		return
+	}
+	if !validasiRentangTidakTerkunci(c, leave.StartDate, leave.EndDate) {
+		return
+	}
+
+	if err := db.Delete(&leave).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data cuti"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// Pengganti tanggal kosong: hire_date kosong => sejak awal, termination_date kosong => tanpa akhir
+var (
+	tanggalPalingAwal  = time.Time{}
+	tanggalPalingAkhir = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// validasiPerubahanTanggal memastikan perubahan tanggal (lama => baru, nil diganti kosong) tidak
+// mengenai periode terkunci, yaitu periode yang overlap dengan rentang di antara kedua tanggal.
+// Jika mengenai, response 409 langsung dikirim dan mengembalikan false.
+func validasiPerubahanTanggal(c *gin.Context, lama, baru *time.Time, kosong time.Time) bool {
+	a, b := kosong, kosong
+	if lama != nil {
+		a = tanggalSaja(*lama)
+	}
+	if baru != nil {
+		b = tanggalSaja(*baru)
+	}
+	if a.Equal(b) {
+		return true
+	}
+	if b.Before(a) {
+		a, b = b, a
+	}
+	return validasiRentangTidakTerkunci(c, a, b)
+}
+
+// parseTanggalOpsional => nil jika string kosong, selain itu parse format "YYYY-MM-DD"
+func parseTanggalOpsional(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
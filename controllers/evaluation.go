@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EvaluationInput adalah payload untuk pembuatan / update evaluasi pegawai per kriteria
+type EvaluationInput struct {
+	EmployeeID  uint    `json:"employee_id" binding:"required"`
+	CriterionID uint    `json:"criterion_id" binding:"required"`
+	Score       float64 `json:"score"` // skala 0-5
+	Comments    string  `json:"comments"`
+	PeriodID    uint    `json:"period_id"`
+}
+
+// GetEvaluations - GET /api/evaluations
+// Opsional: filter ?employee_id=, ?criterion_id= dan ?period_id=
+func GetEvaluations(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	query := filterPeriode(db.Preload("Employee").Preload("Criterion"), periode)
+	if empID := c.Query("employee_id"); empID != "" {
+		query = query.Where("employee_id = ?", empID)
+	}
+	if criterionID := c.Query("criterion_id"); criterionID != "" {
+		query = query.Where("criterion_id = ?", criterionID)
+	}
+
+	var evaluations []models.Evaluation
+	if err := query.Find(&evaluations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data evaluasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": evaluations})
+}
+
+// CreateEvaluation - POST /api/evaluations
+func CreateEvaluation(c *gin.Context) {
+	var input EvaluationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validasiEvaluasi(c, input) || !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+
+	evaluation := models.Evaluation{
+		EmployeeID:  input.EmployeeID,
+		CriterionID: input.CriterionID,
+		Score:       input.Score,
+		Comments:    input.Comments,
+		PeriodID:    input.PeriodID,
+	}
+	if err := db.Omit("Employee", "Criterion").Create(&evaluation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat evaluasi"})
+		return
+	}
+
+	db.Preload("Employee").Preload("Criterion").First(&evaluation, evaluation.ID)
+	c.JSON(http.StatusOK, gin.H{"data": evaluation})
+}
+
+// UpdateEvaluation - PUT /api/evaluations/:id
+func UpdateEvaluation(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var evaluation models.Evaluation
+	if err := db.First(&evaluation, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluasi tidak ditemukan"})
+		return
+	}
+
+	var input EvaluationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validasiEvaluasi(c, input) {
+		return
+	}
+	// Periode lama maupun periode baru tidak boleh terkunci
+	if !validasiPeriodeInput(c, evaluation.PeriodID) || !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+
+	evaluation.EmployeeID = input.EmployeeID
+	evaluation.CriterionID = input.CriterionID
+	evaluation.Score = input.Score
+	evaluation.Comments = input.Comments
+	evaluation.PeriodID = input.PeriodID
+
+	if err := db.Omit("Employee", "Criterion").Save(&evaluation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui evaluasi"})
+		return
+	}
+
+	db.Preload("Employee").Preload("Criterion").First(&evaluation, evaluation.ID)
+	c.JSON(http.StatusOK, gin.H{"data": evaluation})
+}
+
+// DeleteEvaluation - DELETE /api/evaluations/:id
+func DeleteEvaluation(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var evaluation models.Evaluation
+	if err := db.First(&evaluation, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluasi tidak ditemukan"})
+		return
+	}
+	if !validasiPeriodeInput(c, evaluation.PeriodID) {
+		return
+	}
+
+	if err := db.Delete(&evaluation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus evaluasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// validasiEvaluasi memastikan skor di rentang 0-5 serta pegawai & kriteria ada.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func validasiEvaluasi(c *gin.Context, input EvaluationInput) bool {
+	if input.Score < 0 || input.Score > SkorMaksimal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Skor evaluasi harus di antara 0 dan 5"})
+		return false
+	}
+	var employee models.Employee
+	if err := db.First(&employee, input.EmployeeID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pegawai tidak ditemukan"})
+		return false
+	}
+	var criterion models.Criterion
+	if err := db.First(&criterion, input.CriterionID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kriteria tidak ditemukan"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,416 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EvaluationPeriodInput adalah payload untuk pembuatan / update periode evaluasi.
+// Pada update, field opsional yang tidak dikirim tidak mengubah nilai yang tersimpan.
+type EvaluationPeriodInput struct {
+	Name      string `json:"name" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"` // Format "YYYY-MM-DD"
+	EndDate   string `json:"end_date" binding:"required"`   // Format "YYYY-MM-DD"
+	ScaleMode string `json:"scale_mode"`                    // step (default) / linear
+
+	PayoutDate     inputOpsional[string] `json:"payout_date"` // Format "YYYY-MM-DD", null / "" => dikosongkan
+	ProrateKondite *bool                 `json:"prorate_kondite"`
+
+	MaxDeduction    inputOpsional[float64] `json:"max_deduction"`     // batas total pengurang poin, null => tanpa batas
+	MaxRewardPoints inputOpsional[float64] `json:"max_reward_points"` // batas total penambah poin, null => tanpa batas
+	MaxFinalKPI     inputOpsional[float64] `json:"max_final_kpi"`     // plafon KPI setelah kalibrasi, null => tanpa batas
+
+	KPIEvaluationRule string `json:"kpi_evaluation_rule"` // latest (default) / average / max / min
+}
+
+// inputOpsional membedakan field JSON yang tidak dikirim (Ada = false)
+// dengan field yang dikirim bernilai null (Ada = true, Nilai = nil).
+type inputOpsional[T any] struct {
+	Ada   bool
+	Nilai *T
+}
+
+// UnmarshalJSON hanya dipanggil jika field ada pada payload (termasuk null)
+func (o *inputOpsional[T]) UnmarshalJSON(data []byte) error {
+	o.Ada = true
+	if string(data) == "null" {
+		o.Nilai = nil
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.Nilai = &v
+	return nil
+}
+
+// urutanStatusPeriode => urutan siklus status periode, transisi hanya boleh maju satu langkah
+var urutanStatusPeriode = []string{
+	models.PeriodStatusDraft,
+	models.PeriodStatusOpen,
+	models.PeriodStatusCalibration,
+	models.PeriodStatusLocked,
+}
+
+// GetPeriods - GET /api/periods
+func GetPeriods(c *gin.Context) {
+	var periods []models.EvaluationPeriod
+	if err := db.Order("start_date desc").Find(&periods).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data periode"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": periods})
+}
+
+// CreatePeriod - POST /api/periods
+func CreatePeriod(c *gin.Context) {
+	var input EvaluationPeriodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	period := models.EvaluationPeriod{Status: models.PeriodStatusDraft}
+	if !isiPeriode(c, &period, input) {
+		return
+	}
+
+	if err := db.Create(&period).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": period})
+}
+
+// UpdatePeriod - PUT /api/periods/:id
+func UpdatePeriod(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var period models.EvaluationPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	if period.Status == models.PeriodStatusLocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah dikunci, tidak dapat diubah"})
+		return
+	}
+
+	var input EvaluationPeriodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isiPeriode(c, &period, input) {
+		return
+	}
+
+	if err := db.Save(&period).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": period})
+}
+
+// DeletePeriod - DELETE /api/periods/:id
+func DeletePeriod(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var period models.EvaluationPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	if period.Status == models.PeriodStatusLocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah dikunci, tidak dapat dihapus"})
+		return
+	}
+
+	if err := db.Delete(&period).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// isiPeriode memvalidasi input lalu mengisi field periode.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiPeriode(c *gin.Context, period *models.EvaluationPeriod, input EvaluationPeriodInput) bool {
+	start, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format start_date tidak valid (YYYY-MM-DD)"})
+		return false
+	}
+	end, err := time.Parse("2006-01-02", input.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format end_date tidak valid (YYYY-MM-DD)"})
+		return false
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date tidak boleh sebelum start_date"})
+		return false
+	}
+
+	payoutDate := period.PayoutDate
+	if input.PayoutDate.Ada {
+		var teks string
+		if input.PayoutDate.Nilai != nil {
+			teks = *input.PayoutDate.Nilai
+		}
+		if payoutDate, err = parseTanggalOpsional(teks); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format payout_date tidak valid (YYYY-MM-DD)"})
+			return false
+		}
+	}
+
+	// Field yang tidak dikirim => pakai nilai tersimpan (periode baru => default)
+	scaleMode := input.ScaleMode
+	if scaleMode == "" {
+		scaleMode = period.ScaleMode
+	}
+	if scaleMode == "" {
+		scaleMode = models.ScaleModeStep
+	}
+	if scaleMode != models.ScaleModeStep && scaleMode != models.ScaleModeLinear {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scale_mode harus step atau linear"})
+		return false
+	}
+
+	batas := []struct {
+		input *inputOpsional[float64]
+		field **float64
+	}{
+		{&input.MaxDeduction, &period.MaxDeduction},
+		{&input.MaxRewardPoints, &period.MaxRewardPoints},
+		{&input.MaxFinalKPI, &period.MaxFinalKPI},
+	}
+	for _, b := range batas {
+		if b.input.Nilai != nil && *b.input.Nilai < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_deduction, max_reward_points dan max_final_kpi tidak boleh negatif"})
+			return false
+		}
+	}
+
+	aturanEvaluasi := input.KPIEvaluationRule
+	if aturanEvaluasi == "" {
+		aturanEvaluasi = period.KPIEvaluationRule
+	}
+	if aturanEvaluasi == "" {
+		aturanEvaluasi = models.KPIEvaluationRuleLatest
+	}
+	switch aturanEvaluasi {
+	case models.KPIEvaluationRuleLatest, models.KPIEvaluationRuleAverage, models.KPIEvaluationRuleMax, models.KPIEvaluationRuleMin:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kpi_evaluation_rule harus latest, average, max atau min"})
+		return false
+	}
+
+	period.Name = input.Name
+	period.StartDate = start
+	period.EndDate = end
+	period.ScaleMode = scaleMode
+	period.PayoutDate = payoutDate
+	if input.ProrateKondite != nil {
+		period.ProrateKondite = *input.ProrateKondite
+	}
+	for _, b := range batas {
+		if b.input.Ada {
+			*b.field = b.input.Nilai
+		}
+	}
+	period.KPIEvaluationRule = aturanEvaluasi
+	return true
+}
+
+// UpdatePeriodStatus - POST /api/periods/:id/status
+// Memajukan status periode satu langkah: draft -> open -> calibration -> locked.
+func UpdatePeriodStatus(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var period models.EvaluationPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+
+	var input struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if indeksStatusPeriode(input.Status) < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status periode harus draft, open, calibration, atau locked"})
+		return
+	}
+	if indeksStatusPeriode(input.Status) != indeksStatusPeriode(period.Status)+1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transisi status dari " + period.Status + " ke " + input.Status + " tidak diizinkan"})
+		return
+	}
+
+	period.Status = input.Status
+	if err := db.Save(&period).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": period})
+}
+
+// UnlockPeriod - POST /api/periods/:id/unlock (khusus admin)
+// Membuka kembali periode yang terkunci ke status calibration dan mencatat alasannya.
+func UnlockPeriod(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var period models.EvaluationPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	if period.Status != models.PeriodStatusLocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode tidak dalam status locked"})
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alasan (reason) wajib diisi"})
+		return
+	}
+
+	log := models.PeriodUnlockLog{
+		PeriodID:   period.ID,
+		EmployeeID: idPenggunaLogin(c),
+		Reason:     input.Reason,
+		FromStatus: period.Status,
+		ToStatus:   models.PeriodStatusCalibration,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		period.Status = models.PeriodStatusCalibration
+		if err := tx.Save(&period).Error; err != nil {
+			return err
+		}
+		return tx.Create(&log).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": period, "log": log})
+}
+
+// GetPeriodUnlockLogs - GET /api/periods/:id/unlock-logs
+func GetPeriodUnlockLogs(c *gin.Context) {
+	var logs []models.PeriodUnlockLog
+	if err := db.Where("period_id = ?", c.Param("id")).Order("created_at desc").Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat buka kunci"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": logs})
+}
+
+// indeksStatusPeriode => posisi status pada siklus periode (-1 jika tidak dikenal)
+func indeksStatusPeriode(status string) int {
+	for i, s := range urutanStatusPeriode {
+		if s == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// periodeDariQuery membaca ?period_id= dari query string.
+// Mengembalikan nil jika tidak diisi (semua data). Jika periode tidak ditemukan,
+// response error langsung dikirim dan ok = false.
+func periodeDariQuery(c *gin.Context) (*models.EvaluationPeriod, bool) {
+	raw := c.Query("period_id")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_id tidak valid"})
+		return nil, false
+	}
+	return ambilPeriode(c, uint(id))
+}
+
+// ambilPeriode mengambil periode berdasarkan ID (0 => nil, tanpa filter periode).
+// Jika periode tidak ditemukan, response error langsung dikirim dan ok = false.
+func ambilPeriode(c *gin.Context, id uint) (*models.EvaluationPeriod, bool) {
+	if id == 0 {
+		return nil, true
+	}
+	var period models.EvaluationPeriod
+	if err := db.First(&period, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return nil, false
+	}
+	return &period, true
+}
+
+// validasiPeriodeInput memastikan period_id pada data yang ditulis (KPI, kondite, dsb.) valid
+// dan periodenya belum dikunci (409). period_id = 0 diperbolehkan (data tanpa periode).
+// Dipakai untuk period_id baru dari input maupun period_id data lama yang diubah/dihapus.
+func validasiPeriodeInput(c *gin.Context, periodID uint) bool {
+	if periodID == 0 {
+		return true
+	}
+	var period models.EvaluationPeriod
+	if err := db.First(&period, periodID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Periode tidak ditemukan"})
+		return false
+	}
+	if period.Status == models.PeriodStatusLocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode " + period.Name + " sudah dikunci, data tidak dapat diubah"})
+		return false
+	}
+	return true
+}
+
+// validasiRentangTidakTerkunci memastikan tidak ada periode terkunci yang overlap dengan rentang tanggal
+// (dipakai untuk data yang memengaruhi periode berdasarkan tanggal, mis. cuti pegawai).
+// Jika ada, response 409 langsung dikirim dan mengembalikan false.
+func validasiRentangTidakTerkunci(c *gin.Context, start, end time.Time) bool {
+	var period models.EvaluationPeriod
+	err := db.Where("status = ? AND start_date <= ? AND end_date >= ?", models.PeriodStatusLocked, end, start).
+		First(&period).Error
+	if err == gorm.ErrRecordNotFound {
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa periode terkunci"})
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Periode " + period.Name + " sudah dikunci, data tidak dapat diubah"})
+	return false
+}
+
+// modeSkalaPeriode => mode skala periode (tanpa periode => step)
+func modeSkalaPeriode(period *models.EvaluationPeriod) string {
+	if period == nil || period.ScaleMode == "" {
+		return models.ScaleModeStep
+	}
+	return period.ScaleMode
+}
+
+// filterPeriode menambahkan kondisi period_id pada query jika periode diisi
+func filterPeriode(query *gorm.DB, period *models.EvaluationPeriod) *gorm.DB {
+	if period == nil {
+		return query
+	}
+	return query.Where("period_id = ?", period.ID)
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"math"
 	"net/http"
+	"time"
 
 	"bonus/models"
 
@@ -13,7 +15,9 @@ import (
 // KalibrasiResponse merepresentasikan struktur data yang akan dikembalikan ke front-end.
 type KalibrasiResponse struct {
 	No                  int     `json:"no"`
+	EmployeeID          uint    `json:"employee_id"`
 	Name                string  `json:"name"`
+	Department          string  `json:"department"`
 	KPIPerusahaan       float64 `json:"kpi_perusahaan"`
 	KPIDepart           float64 `json:"kpi_depart"`
 	KPIIndividu         float64 `json:"kpi_individu"`
@@ -22,78 +26,340 @@ type KalibrasiResponse struct {
 	PenambahPoin        float64 `json:"penambah_poin"`
 	KPISetelahKalibrasi float64 `json:"kpi_setelah_kalibrasi"`
 	Skala               string  `json:"skala"`
+	SkalaAlami          string  `json:"skala_alami,omitempty"` // skala berdasarkan ambang (hanya mode forced)
+	Multiplier          float64 `json:"multiplier"`
+	FaktorProrata       float64 `json:"faktor_prorata"` // proporsi hari kerja dalam periode
 	Gaji                float64 `json:"gaji"`
 	Bonus               float64 `json:"bonus"`
+
+	// Eligibilitas bonus: jika tidak eligible, bonus = 0 dan AturanGagal berisi nama aturan
+	Eligible    bool   `json:"eligible"`
+	AturanGagal string `json:"aturan_gagal,omitempty"`
+
+	// Skor per kriteria (hanya jika bobot kriteria tersimpan dipakai)
+	SkorKriteria map[string]float64 `json:"skor_kriteria,omitempty"`
+
+	// Kondite yang masa berlakunya overlap dengan periode dan ikut mengurangi poin
+	KonditeTerhitung []KontribusiKondite `json:"kondite_terhitung"`
+
+	// Reward approved pada periode yang ikut menambah poin
+	RewardTerhitung []KontribusiReward `json:"reward_terhitung"`
+
+	// Batas / lantai yang membatasi nilai pegawai ini (kosong jika tidak ada)
+	BatasDiterapkan []BatasTerapan `json:"batas_diterapkan"`
+
+	// Override komite kalibrasi yang diterapkan (nil jika tidak ada)
+	Override *InfoOverride `json:"override,omitempty"`
+
+	// Asal skor setiap KPI (penilaian KPI approved atau skor tersimpan)
+	SumberSkorKPI []SumberSkorKPI `json:"sumber_skor_kpi"`
+
+	// Nilai sebelum pembulatan, dipakai untuk peringkat distribusi paksa (tidak dikirim ke front-end)
+	mentah nilaiMentah
+}
+
+// nilaiMentah => KPI setelah kalibrasi, total KPI & pengurang poin tanpa pembulatan
+type nilaiMentah struct {
+	KPISetelahKalibrasi float64
+	TotalKPI            float64
+	PengurangPoin       float64
+}
+
+// Nama aturan batas kalibrasi
+const (
+	BatasPengurang = "max_deduction"
+	BatasPenambah  = "max_reward_points"
+	BatasKPIMaks   = "max_final_kpi"
+	BatasKPIMin    = "min_final_kpi" // lantai 0
+)
+
+// BatasTerapan => satu aturan batas yang mengubah nilai pegawai
+type BatasTerapan struct {
+	Aturan    string  `json:"aturan"`
+	NilaiAwal float64 `json:"nilai_awal"`
+	Batas     float64 `json:"batas"`
+}
+
+// KontribusiKondite => rincian pengurangan poin dari satu kondite
+type KontribusiKondite struct {
+	KonditeID   uint      `json:"kondite_id"`
+	Category    string    `json:"category"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	MinPoint    float64   `json:"min_point"`
+	HariOverlap int       `json:"hari_overlap,omitempty"` // hanya jika periode diisi
+	Poin        float64   `json:"poin"`                   // poin yang benar-benar dikurangkan
+}
+
+// KalibrasiInput merekam data mentah yang dipakai dalam perhitungan kalibrasi (untuk snapshot).
+type KalibrasiInput struct {
+	KPIs          []models.KPI                 `json:"kpis"`
+	Kondites      []models.Kondite             `json:"kondites"`
+	Rewards       []models.Reward              `json:"rewards"`
+	Overrides     []models.CalibrationOverride `json:"overrides"`
+	Skala         []models.ScaleBand           `json:"skala"`
+	ModeSkala     string                       `json:"mode_skala"`
+	Gaji          map[uint]float64             `json:"gaji"` // employee_id => gaji
+	BobotKriteria map[string]float64           `json:"bobot_kriteria"`
+
+	AturanEligibilitas []models.EligibilityRule `json:"aturan_eligibilitas"`
+}
+
+// HasilKalibrasi => baris kalibrasi beserta input yang dipakai untuk menghitungnya
+type HasilKalibrasi struct {
+	Rows       []KalibrasiResponse
+	Input      KalibrasiInput
+	TabelSkala *models.ScaleTable // nil => SkalaDefault
 }
 
-// GetKalibrasi - GET /api/kalibrasi
+// OpsiKalibrasi mengatur perhitungan kalibrasi
+type OpsiKalibrasi struct {
+	Periode *models.EvaluationPeriod // nil => seluruh data tanpa filter periode
+
+	// Override untuk simulasi (nil/kosong => konfigurasi tersimpan)
+	Bands           []models.ScaleBand
+	ModeSkala       string
+	BobotKriteria   map[string]float64
+	PenyesuaianSkor map[uint]float64 // employee_id => tambahan/pengurangan KPI setelah kalibrasi
+}
+
+// ErrBobotKriteria => bobot kriteria tersimpan tidak valid (total tidak sama dengan 1)
+var ErrBobotKriteria = errors.New("total bobot kriteria harus 1")
+
+// GetKalibrasi - GET /api/kalibrasi?period_id=&mode=&scope=&target=
 // Menampilkan hasil kalibrasi KPI setiap karyawan TANPA otentikasi/role.
+// Jika period_id diisi, hanya data KPI/kondite pada periode tersebut yang dihitung.
+// mode=forced => skala ditentukan oleh kuota distribusi (scope: company / department,
+// target: "Exceptional:10,Outstanding:20,Good:40,Fair:20,Poor:10").
 func GetKalibrasi(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	mode := c.DefaultQuery("mode", ModeKalibrasiAmbang)
+	cakupan := c.DefaultQuery("scope", CakupanPerusahaan)
+	if mode != ModeKalibrasiAmbang && mode != ModeKalibrasiPaksa {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode harus threshold atau forced"})
+		return
+	}
+	if cakupan != CakupanPerusahaan && cakupan != CakupanDepartemen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope harus company atau department"})
+		return
+	}
+
+	hasil, err := HitungKalibrasi(OpsiKalibrasi{Periode: periode})
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+
+	// Return JSON tanpa unauthorized
+	response := gin.H{
+		"data":           hasil.Rows,
+		"bobot_kriteria": hasil.Input.BobotKriteria,
+		"periode":        periode,
+		"tabel_skala":    hasil.TabelSkala,
+		"mode_skala":     hasil.Input.ModeSkala,
+		"mode":           mode,
+	}
+
+	if mode == ModeKalibrasiPaksa {
+		target, err := ParseTargetDistribusi(c.Query("target"), hasil.Input.Skala)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		response["distribusi"] = gin.H{
+			"scope":     cakupan,
+			"target":    target,
+			"tie_break": UrutanTieBreak,
+			"laporan":   TerapkanDistribusiPaksa(hasil.Rows, hasil.Input.Skala, target, cakupan),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// HitungKalibrasi menghitung KPI setelah kalibrasi, skala & bonus untuk semua pegawai.
+// Dipakai oleh GetKalibrasi maupun finalisasi snapshot agar hasilnya selalu sama. Error saat mengambil
+// data salah satu pegawai menggagalkan seluruh perhitungan (tidak ada pegawai yang hilang diam-diam).
+func HitungKalibrasi(opsi OpsiKalibrasi) (*HasilKalibrasi, error) {
 	// Ambil semua employees (tanpa cek role)
 	var employees []models.Employee
 	if err := db.Find(&employees).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pegawai"})
-		return
+		return nil, err
+	}
+
+	// Bobot kriteria tersimpan (tabel criteria). Jika kosong => rumus default
+	// KPI setelah kalibrasi = total KPI - pengurang + penambah
+	bobot := opsi.BobotKriteria
+	if bobot == nil {
+		tersimpan, err := BobotKriteriaTersimpan()
+		if err != nil {
+			return nil, err
+		}
+		bobot = tersimpan
+	}
+
+	// Tabel skala yang berlaku untuk periode
+	tabelSkala, bands, err := SkalaUntukPeriode(opsi.Periode)
+	if err != nil {
+		return nil, err
+	}
+	if len(opsi.Bands) > 0 {
+		tabelSkala, bands = nil, opsi.Bands
 	}
+	// Aturan eligibilitas bonus
+	rules, err := aturanEligibilitasAktif()
+	if err != nil {
+		return nil, err
+	}
+	// Batas poin reward per kategori
+	batasReward, err := batasPoinReward()
+	if err != nil {
+		return nil, err
+	}
+	// Override komite kalibrasi yang sudah disetujui
+	overrides, err := overrideKalibrasiDisetujui(opsi.Periode)
+	if err != nil {
+		return nil, err
+	}
+
+	modeSkala := modeSkalaPeriode(opsi.Periode)
+	if opsi.ModeSkala != "" {
+		modeSkala = opsi.ModeSkala
+	}
+
+	hasil := &HasilKalibrasi{
+		Rows: []KalibrasiResponse{},
+		Input: KalibrasiInput{
+			KPIs:          []models.KPI{},
+			Kondites:      []models.Kondite{},
+			Rewards:       []models.Reward{},
+			Overrides:     []models.CalibrationOverride{},
+			Skala:         bands,
+			ModeSkala:     modeSkala,
+			Gaji:          map[uint]float64{},
+			BobotKriteria: bobot,
 
-	results := []KalibrasiResponse{}
+			AturanEligibilitas: rules,
+		},
+		TabelSkala: tabelSkala,
+	}
 	nomor := 1
 
 	// Loop setiap pegawai => hitung KPI & bonus
 	for _, emp := range employees {
-		// Ambil KPI milik pegawai ini
-		var kpis []models.KPI
-		err := db.Where("employee_id = ?", emp.ID).Find(&kpis).Error
-		if err != nil && err != gorm.ErrRecordNotFound {
-			continue
-		}
-
-		// Pisahkan total KPI berdasarkan kategori
-		var totalPerusahaan float64
-		var totalDept float64
-		var totalInd float64
-
-		// Loop setiap KPI, hitung finalScore = Score * (Weight / 100)
-		for _, k := range kpis {
-			finalScore := k.Score * (k.Weight / 100.0)
-			switch k.Category {
-			case "Perusahaan":
-				totalPerusahaan += finalScore
-			case "Dept", "Departemen":
-				totalDept += finalScore
-			case "Individu":
-				totalInd += finalScore
-			}
+		// Ambil KPI milik pegawai ini, lalu pisahkan total KPI berdasarkan kategori
+		kpis, err := ambilKPIPegawai(emp.ID, opsi.Periode)
+		if err != nil {
+			return nil, err
+		}
+		sumberSkor, err := TerapkanEvaluasiKPI(kpis, opsi.Periode)
+		if err != nil {
+			return nil, err
 		}
+		totalPerusahaan, totalDept, totalInd := totalKPIPerKategori(kpis)
 
 		// Total KPI sebelum kalibrasi
 		totalKPI := totalPerusahaan + totalDept + totalInd
 
 		// Pengurang poin => dari Kondite
-		pengurang, _ := HitungPengurangPoin(emp.ID)
+		kondites, err := ambilKonditePegawai(emp.ID, opsi.Periode)
+		if err != nil {
+			return nil, err
+		}
+		kontribusi, pengurang := HitungKontribusiKondite(kondites, opsi.Periode)
 
-		// Penambah poin => jika ada reward dsb. (default 0)
-		penambah, _ := HitungPenambahPoin(emp.ID)
+		// Penambah poin => dari reward approved
+		rewards, err := ambilRewardPegawai(emp.ID, opsi.Periode)
+		if err != nil {
+			return nil, err
+		}
+		kontribusiReward, penambah := HitungKontribusiReward(rewards, batasReward)
+
+		// Batas total pengurang & penambah poin per periode
+		batasTerapan := []BatasTerapan{}
+		if opsi.Periode != nil {
+			pengurang = terapkanBatasAtas(pengurang, opsi.Periode.MaxDeduction, BatasPengurang, &batasTerapan)
+			penambah = terapkanBatasAtas(penambah, opsi.Periode.MaxRewardPoints, BatasPenambah, &batasTerapan)
+		}
 
 		// Final KPI setelah kalibrasi
 		finalKPI := totalKPI - pengurang + penambah
+		var skorKriteria map[string]float64
+		if len(bobot) > 0 {
+			skorKriteria, err = SkorKriteriaPegawai(emp.ID, opsi.Periode, totalKPI, pengurang, penambah, namaKriteria(bobot))
+			if err != nil {
+				return nil, err
+			}
+			finalKPI = SkorTerbobot(bobot, skorKriteria)
+		}
+		finalKPI += opsi.PenyesuaianSkor[emp.ID]
 		if finalKPI < 0 {
+			batasTerapan = append(batasTerapan, BatasTerapan{Aturan: BatasKPIMin, NilaiAwal: RoundFloat(finalKPI, 3), Batas: 0})
 			finalKPI = 0
 		}
+		if opsi.Periode != nil {
+			finalKPI = terapkanBatasAtas(finalKPI, opsi.Periode.MaxFinalKPI, BatasKPIMaks, &batasTerapan)
+		}
 
 		// Skala & multiplier bonus
-		skala, multiplier := SkalaKPI(finalKPI)
+		band := TentukanBand(bands, finalKPI)
+		multiplier := HitungMultiplier(bands, finalKPI, hasil.Input.ModeSkala)
+
+		// Override komite: KPI akhir atau skala diganti, nilai hasil hitung tetap dilaporkan
+		var infoOverride *InfoOverride
+		if o, ok := overrides[emp.ID]; ok {
+			infoOverride = &InfoOverride{
+				OverrideID:    o.ID,
+				KPIDihitung:   RoundFloat(finalKPI, 1),
+				SkalaDihitung: band.Label,
+				FinalKPI:      o.FinalKPI,
+				Scale:         o.Scale,
+				Reason:        o.Reason,
+				CreatedBy:     o.CreatedBy,
+				ApprovedBy:    o.ApprovedBy,
+			}
+			if o.FinalKPI != nil {
+				// Plafon KPI periode tetap berlaku untuk KPI hasil override
+				finalKPI = *o.FinalKPI
+				if opsi.Periode != nil {
+					finalKPI = terapkanBatasAtas(finalKPI, opsi.Periode.MaxFinalKPI, BatasKPIMaks, &batasTerapan)
+				}
+				band = TentukanBand(bands, finalKPI)
+				multiplier = HitungMultiplier(bands, finalKPI, hasil.Input.ModeSkala)
+			}
+			if b, ok := bandBerdasarkanLabel(bands, o.Scale); ok {
+				band = b
+				multiplier = b.Multiplier
+			}
+			hasil.Input.Overrides = append(hasil.Input.Overrides, o)
+		}
 
 		// Gaji
 		gaji := float64(emp.Salary)
 
-		// Bonus
-		bonus := gaji * multiplier
+		// Prorata untuk pegawai yang masuk/keluar di tengah periode atau cuti tidak dibayar
+		prorata, err := HitungFaktorProrata(emp, opsi.Periode)
+		if err != nil {
+			return nil, err
+		}
+
+		// Bonus (0 jika tidak memenuhi aturan eligibilitas)
+		bonus := HitungBonusBand(band, gaji, multiplier) * prorata
+		eligible, aturanGagal := EvaluasiEligibilitas(rules, emp, opsi.Periode, finalKPI, kondites)
+		if !eligible {
+			bonus = 0
+		}
 
 		// Buat item response
 		item := KalibrasiResponse{
 			No:                  nomor,
+			EmployeeID:          emp.ID,
 			Name:                emp.Name,
+			Department:          emp.Department,
 			KPIPerusahaan:       RoundFloat(totalPerusahaan, 1),
 			KPIDepart:           RoundFloat(totalDept, 1),
 			KPIIndividu:         RoundFloat(totalInd, 1),
@@ -101,58 +367,234 @@ func GetKalibrasi(c *gin.Context) {
 			PengurangPoin:       RoundFloat(pengurang, 1),
 			PenambahPoin:        RoundFloat(penambah, 1),
 			KPISetelahKalibrasi: RoundFloat(finalKPI, 1),
-			Skala:               skala,
+			Skala:               band.Label,
+			Multiplier:          RoundFloat(multiplier, 3),
+			FaktorProrata:       RoundFloat(prorata, 4),
+			SkorKriteria:        skorKriteria,
 			Gaji:                gaji,
 			Bonus:               bonus,
+			Eligible:            eligible,
+			AturanGagal:         aturanGagal,
+			KonditeTerhitung:    kontribusi,
+			RewardTerhitung:     kontribusiReward,
+			BatasDiterapkan:     batasTerapan,
+			Override:            infoOverride,
+			SumberSkorKPI:       sumberSkor,
+			mentah:              nilaiMentah{KPISetelahKalibrasi: finalKPI, TotalKPI: totalKPI, PengurangPoin: pengurang},
 		}
-		results = append(results, item)
+		hasil.Rows = append(hasil.Rows, item)
+		hasil.Input.KPIs = append(hasil.Input.KPIs, kpis...)
+		hasil.Input.Kondites = append(hasil.Input.Kondites, kondites...)
+		hasil.Input.Rewards = append(hasil.Input.Rewards, rewards...)
+		hasil.Input.Gaji[emp.ID] = gaji
 		nomor++
 	}
 
-	// Return JSON tanpa unauthorized
-	c.JSON(http.StatusOK, gin.H{"data": results})
+	return hasil, nil
 }
 
-// HitungPengurangPoin => contoh perhitungan total min_point dari Kondite
-func HitungPengurangPoin(empID uint) (float64, error) {
-	var kondites []models.Kondite
-	err := db.Where("employee_id = ?", empID).Find(&kondites).Error
+// kirimErrorKalibrasi => 422 untuk konfigurasi yang tidak valid, 500 untuk error lain
+func kirimErrorKalibrasi(c *gin.Context, err error) {
+	if errors.Is(err, ErrBobotKriteria) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung kalibrasi"})
+}
+
+// HitungKPIPegawai => total KPI (Score * Weight / 100) per kategori: Perusahaan, Departemen, Individu
+// Jika periode diisi, hanya KPI pada periode tersebut yang dihitung. Score diambil dari penilaian KPI approved.
+func HitungKPIPegawai(empID uint, periode *models.EvaluationPeriod) (float64, float64, float64, error) {
+	kpis, err := ambilKPIPegawai(empID, periode)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if _, err := TerapkanEvaluasiKPI(kpis, periode); err != nil {
+		return 0, 0, 0, err
+	}
+	perusahaan, dept, ind := totalKPIPerKategori(kpis)
+	return perusahaan, dept, ind, nil
+}
+
+// ambilKPIPegawai => daftar penugasan KPI milik pegawai beserta templatenya (opsional: pada periode tertentu)
+func ambilKPIPegawai(empID uint, periode *models.EvaluationPeriod) ([]models.KPI, error) {
+	var kpis []models.KPI
+	err := filterPeriode(db.Preload("Template").Where("employee_id = ?", empID), periode).Find(&kpis).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return kpis, nil
+}
+
+// totalKPIPerKategori => finalScore = Score * (bobot efektif / 100), dijumlahkan per kategori template
+func totalKPIPerKategori(kpis []models.KPI) (float64, float64, float64) {
+	var totalPerusahaan, totalDept, totalInd float64
+	for _, k := range kpis {
+		finalScore := k.Score * (bobotKPI(k) / 100.0)
+		switch normalisasiKategoriKPI(k.Template.Category) {
+		case "Perusahaan":
+			totalPerusahaan += finalScore
+		case "Departemen":
+			totalDept += finalScore
+		case "Individu":
+			totalInd += finalScore
+		}
+	}
+	return totalPerusahaan, totalDept, totalInd
+}
+
+// HitungPengurangPoin => total pengurang poin dari kondite yang berlaku pada periode (setelah batas periode)
+func HitungPengurangPoin(empID uint, periode *models.EvaluationPeriod) (float64, error) {
+	kondites, err := ambilKonditePegawai(empID, periode)
 	if err != nil {
 		return 0, err
 	}
+	_, total := HitungKontribusiKondite(kondites, periode)
+	if periode != nil {
+		total = terapkanBatasAtas(total, periode.MaxDeduction, BatasPengurang, &[]BatasTerapan{})
+	}
+	return total, nil
+}
+
+// ambilKonditePegawai => kondite milik pegawai yang masa berlakunya (start_date s.d. end_date)
+// overlap dengan periode (perubahan kondite ditolak jika mengenai periode terkunci).
+// Tanpa periode => seluruh kondite pegawai. Hanya kondite berstatus active / upheld yang dihitung.
+func ambilKonditePegawai(empID uint, periode *models.EvaluationPeriod) ([]models.Kondite, error) {
+	query := db.Where("employee_id = ? AND status IN ?", empID, statusKonditeBerlaku)
+	if periode != nil {
+		query = query.Where("start_date <= ? AND end_date >= ?", periode.EndDate, periode.StartDate)
+	}
+
+	var kondites []models.Kondite
+	if err := query.Find(&kondites).Error; err != nil {
+		return nil, err
+	}
+	return kondites, nil
+}
+
+// HitungKontribusiKondite => rincian & total pengurang poin. Jika periode mengaktifkan
+// prorate_kondite, MinPoint dikalikan (hari overlap / jumlah hari periode).
+func HitungKontribusiKondite(kondites []models.Kondite, periode *models.EvaluationPeriod) ([]KontribusiKondite, float64) {
+	kontribusi := []KontribusiKondite{}
 	var total float64
 	for _, k := range kondites {
-		// Asumsi MinPoint adalah float64 atau int => sesuaikan
-		total += float64(k.MinPoint)
+		item := KontribusiKondite{
+			KonditeID: k.ID,
+			Category:  k.Category,
+			StartDate: k.StartDate,
+			EndDate:   k.EndDate,
+			MinPoint:  k.MinPoint,
+			Poin:      k.MinPoint,
+		}
+		if periode != nil {
+			mulai, selesai := tanggalSaja(periode.StartDate), tanggalSaja(periode.EndDate)
+			awal, akhir := tanggalSaja(k.StartDate), tanggalSaja(k.EndDate)
+			if awal.Before(mulai) {
+				awal = mulai
+			}
+			if akhir.After(selesai) {
+				akhir = selesai
+			}
+			item.HariOverlap = hariInklusif(awal, akhir)
+			if periode.ProrateKondite {
+				item.Poin = RoundFloat(k.MinPoint*float64(item.HariOverlap)/float64(hariInklusif(mulai, selesai)), 3)
+			}
+		}
+		total += item.Poin
+		kontribusi = append(kontribusi, item)
 	}
-	return total, nil
+	return kontribusi, total
+}
+
+// terapkanBatasAtas => min(nilai, batas). Jika batas memotong nilai, aturan dicatat pada daftar.
+func terapkanBatasAtas(nilai float64, batas *float64, aturan string, daftar *[]BatasTerapan) float64 {
+	if batas == nil || nilai <= *batas {
+		return nilai
+	}
+	*daftar = append(*daftar, BatasTerapan{Aturan: aturan, NilaiAwal: RoundFloat(nilai, 3), Batas: *batas})
+	return *batas
 }
 
-// HitungPenambahPoin => contoh, jika ada reward dsb.
-func HitungPenambahPoin(empID uint) (float64, error) {
-	// Default 0, diisi jika ada logika penambahan
-	return 0, nil
+// HitungPenambahPoin => total penambah poin dari reward approved pada periode (setelah batas kategori & periode)
+func HitungPenambahPoin(empID uint, periode *models.EvaluationPeriod) (float64, error) {
+	rewards, err := ambilRewardPegawai(empID, periode)
+	if err != nil {
+		return 0, err
+	}
+	batas, err := batasPoinReward()
+	if err != nil {
+		return 0, err
+	}
+	_, total := HitungKontribusiReward(rewards, batas)
+	if periode != nil {
+		total = terapkanBatasAtas(total, periode.MaxRewardPoints, BatasPenambah, &[]BatasTerapan{})
+	}
+	return total, nil
 }
 
-// SkalaKPI => mengembalikan keterangan & multiplier bonus
+// SkalaDefault => tabel skala bawaan (dipakai jika belum ada tabel skala tersimpan)
 // < 2 => "Poor" => multiplier=1
 // < 3 => "Fair" => multiplier=2
 // < 4 => "Good" => multiplier=3
 // < 5 => "Outstanding" => multiplier=4
 // >= 5 => "Exceptional" => multiplier=5
+var SkalaDefault = []models.ScaleBand{
+	{Label: "Poor", LowerBound: 0, UpperBound: floatPtr(2), Multiplier: 1},
+	{Label: "Fair", LowerBound: 2, UpperBound: floatPtr(3), Multiplier: 2},
+	{Label: "Good", LowerBound: 3, UpperBound: floatPtr(4), Multiplier: 3},
+	{Label: "Outstanding", LowerBound: 4, UpperBound: floatPtr(5), Multiplier: 4},
+	{Label: "Exceptional", LowerBound: 5, Multiplier: 5},
+}
+
+// SkalaKPI => mengembalikan keterangan & multiplier bonus berdasarkan SkalaDefault
 func SkalaKPI(final float64) (string, float64) {
-	switch {
-	case final < 2:
-		return "Poor", 1
-	case final < 3:
-		return "Fair", 2
-	case final < 4:
-		return "Good", 3
-	case final < 5:
-		return "Outstanding", 4
-	default:
-		return "Exceptional", 5
+	band := TentukanBand(SkalaDefault, final)
+	return band.Label, band.Multiplier
+}
+
+// TentukanBand => band tempat KPI akhir berada (band terbawah/teratas jika di luar rentang).
+// Bands harus sudah terurut berdasarkan LowerBound.
+func TentukanBand(bands []models.ScaleBand, final float64) models.ScaleBand {
+	for _, band := range bands {
+		if band.UpperBound == nil || final < *band.UpperBound {
+			return band
+		}
 	}
+	return bands[len(bands)-1]
+}
+
+// HitungMultiplier => multiplier bonus untuk KPI akhir.
+// Mode step: multiplier band. Mode linear: interpolasi linear antara titik (LowerBound, Multiplier)
+// band yang mengapit KPI akhir; di bawah band pertama / di atas band terakhir memakai multiplier band tersebut.
+func HitungMultiplier(bands []models.ScaleBand, final float64, mode string) float64 {
+	if mode != models.ScaleModeLinear {
+		return TentukanBand(bands, final).Multiplier
+	}
+
+	if final <= bands[0].LowerBound {
+		return bands[0].Multiplier
+	}
+	for i := 0; i < len(bands)-1; i++ {
+		a, b := bands[i], bands[i+1]
+		if final < b.LowerBound {
+			rasio := (final - a.LowerBound) / (b.LowerBound - a.LowerBound)
+			return a.Multiplier + rasio*(b.Multiplier-a.Multiplier)
+		}
+	}
+	return bands[len(bands)-1].Multiplier
+}
+
+// HitungBonusBand => nominal tetap jika band memilikinya, selain itu gaji * multiplier
+func HitungBonusBand(band models.ScaleBand, gaji, multiplier float64) float64 {
+	if band.FixedAmount != nil {
+		return *band.FixedAmount
+	}
+	return gaji * multiplier
+}
+
+// floatPtr => pointer ke nilai float64 (untuk field opsional)
+func floatPtr(v float64) *float64 {
+	return &v
 }
 
 // RoundFloat => membulatkan float ke n desimal
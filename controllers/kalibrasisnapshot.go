@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SnapshotResponse => snapshot kalibrasi beserta isi yang sudah di-decode
+type SnapshotResponse struct {
+	models.KalibrasiSnapshot
+	Results []KalibrasiResponse `json:"results"`
+	Inputs  *KalibrasiInput     `json:"inputs,omitempty"`
+}
+
+// SelisihSnapshot => perbandingan hasil kalibrasi satu pegawai antara dua versi snapshot
+type SelisihSnapshot struct {
+	EmployeeID   uint               `json:"employee_id"`
+	Name         string             `json:"name"`
+	Status       string             `json:"status"` // added / removed / changed / unchanged
+	Dari         *KalibrasiResponse `json:"dari"`
+	Ke           *KalibrasiResponse `json:"ke"`
+	SelisihKPI   float64            `json:"selisih_kpi"`
+	SelisihBonus float64            `json:"selisih_bonus"`
+}
+
+// maksPercobaanSnapshot => batas percobaan menyimpan snapshot saat nomor versi bentrok
+const maksPercobaanSnapshot = 3
+
+// FinalizeKalibrasi - POST /api/kalibrasi/snapshots
+// Menghitung kalibrasi untuk periode lalu menyimpannya sebagai snapshot versi baru.
+func FinalizeKalibrasi(c *gin.Context) {
+	var input struct {
+		PeriodID uint   `json:"period_id" binding:"required"`
+		Note     string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periode, ok := ambilPeriode(c, input.PeriodID)
+	if !ok {
+		return
+	}
+
+	// Semua sheet KPI pada periode harus valid sebelum difinalisasi
+	tidakValid, err := sheetKPITidakValid(periode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa sheet KPI"})
+		return
+	}
+	if len(tidakValid) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Sebagian sheet KPI belum valid", "data": tidakValid})
+		return
+	}
+
+	hasil, err := HitungKalibrasi(OpsiKalibrasi{Periode: periode})
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+
+	results, err := json.Marshal(hasil.Rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan snapshot kalibrasi"})
+		return
+	}
+	inputs, err := json.Marshal(hasil.Input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan snapshot kalibrasi"})
+		return
+	}
+
+	snapshot := models.KalibrasiSnapshot{
+		PeriodID:    periode.ID,
+		FinalizedBy: idPenggunaLogin(c),
+		Note:        input.Note,
+		Results:     string(results),
+		Inputs:      string(inputs),
+	}
+
+	// Nomor versi = versi terakhir pada periode + 1. Jika finalisasi lain menyimpan versi yang sama
+	// lebih dulu (unique index period_id + version), ulangi dengan versi berikutnya.
+	for percobaan := 0; percobaan < maksPercobaanSnapshot; percobaan++ {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var versiTerakhir int
+			if err := tx.Model(&models.KalibrasiSnapshot{}).
+				Where("period_id = ?", periode.ID).
+				Select("COALESCE(MAX(version), 0)").
+				Scan(&versiTerakhir).Error; err != nil {
+				return err
+			}
+			snapshot.ID = 0
+			snapshot.Version = versiTerakhir + 1
+			return tx.Create(&snapshot).Error
+		})
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			break
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan snapshot kalibrasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": SnapshotResponse{KalibrasiSnapshot: snapshot, Results: hasil.Rows}})
+}
+
+// GetKalibrasiSnapshots - GET /api/kalibrasi/snapshots?period_id=
+// Daftar snapshot (tanpa isi), terbaru lebih dulu.
+func GetKalibrasiSnapshots(c *gin.Context) {
+	query := db.Order("period_id desc, version desc")
+	if periodID := c.Query("period_id"); periodID != "" {
+		query = query.Where("period_id = ?", periodID)
+	}
+
+	var snapshots []models.KalibrasiSnapshot
+	if err := query.Find(&snapshots).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data snapshot"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": snapshots})
+}
+
+// GetKalibrasiSnapshot - GET /api/kalibrasi/snapshots/:id
+// Mengambil satu snapshot lengkap dengan hasil & input perhitungannya.
+func GetKalibrasiSnapshot(c *gin.Context) {
+	snapshot, ok := ambilSnapshot(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var inputs KalibrasiInput
+	if err := json.Unmarshal([]byte(snapshot.KalibrasiSnapshot.Inputs), &inputs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Data input snapshot rusak"})
+		return
+	}
+	snapshot.Inputs = &inputs
+
+	c.JSON(http.StatusOK, gin.H{"data": snapshot})
+}
+
+// DiffKalibrasiSnapshot - GET /api/kalibrasi/snapshots/:id/diff?to=<id>
+// Membandingkan hasil kalibrasi per pegawai antara snapshot :id dan snapshot "to".
+func DiffKalibrasiSnapshot(c *gin.Context) {
+	dari, ok := ambilSnapshot(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	ke, ok := ambilSnapshot(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	barisDari := map[uint]*KalibrasiResponse{}
+	for i := range dari.Results {
+		barisDari[dari.Results[i].EmployeeID] = &dari.Results[i]
+	}
+
+	selisih := []SelisihSnapshot{}
+	var totalSelisihBonus float64
+	for i := range ke.Results {
+		baru := &ke.Results[i]
+		item := SelisihSnapshot{EmployeeID: baru.EmployeeID, Name: baru.Name, Ke: baru, Status: "added"}
+		if lama, ada := barisDari[baru.EmployeeID]; ada {
+			item.Dari = lama
+			item.SelisihKPI = RoundFloat(baru.KPISetelahKalibrasi-lama.KPISetelahKalibrasi, 1)
+			item.SelisihBonus = baru.Bonus - lama.Bonus
+			item.Status = "unchanged"
+			if item.SelisihKPI != 0 || item.SelisihBonus != 0 || baru.Skala != lama.Skala {
+				item.Status = "changed"
+			}
+			delete(barisDari, baru.EmployeeID)
+		} else {
+			item.SelisihKPI = baru.KPISetelahKalibrasi
+			item.SelisihBonus = baru.Bonus
+		}
+		totalSelisihBonus += item.SelisihBonus
+		selisih = append(selisih, item)
+	}
+	// Pegawai yang ada di versi lama tetapi tidak ada di versi baru
+	for i := range dari.Results {
+		lama := &dari.Results[i]
+		if _, sisa := barisDari[lama.EmployeeID]; !sisa {
+			continue
+		}
+		selisih = append(selisih, SelisihSnapshot{
+			EmployeeID:   lama.EmployeeID,
+			Name:         lama.Name,
+			Status:       "removed",
+			Dari:         lama,
+			SelisihKPI:   -lama.KPISetelahKalibrasi,
+			SelisihBonus: -lama.Bonus,
+		})
+		totalSelisihBonus -= lama.Bonus
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dari":                dari.KalibrasiSnapshot,
+		"ke":                  ke.KalibrasiSnapshot,
+		"data":                selisih,
+		"total_selisih_bonus": totalSelisihBonus,
+	})
+}
+
+// ambilSnapshot mengambil snapshot berdasarkan ID dan men-decode hasilnya.
+// Jika tidak ditemukan, response error langsung dikirim dan ok = false.
+func ambilSnapshot(c *gin.Context, id string) (*SnapshotResponse, bool) {
+	var snapshot models.KalibrasiSnapshot
+	if err := db.First(&snapshot, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot kalibrasi " + id + " tidak ditemukan"})
+		return nil, false
+	}
+
+	resp := &SnapshotResponse{KalibrasiSnapshot: snapshot}
+	if err := json.Unmarshal([]byte(snapshot.Results), &resp.Results); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Data snapshot rusak"})
+		return nil, false
+	}
+	return resp, true
+}
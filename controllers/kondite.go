@@ -11,10 +11,15 @@ import (
 
 // GetKondites - GET /api/kondites
 // Mengambil daftar kondite (opsional: boleh filter by employee_id).
-// GET /api/kondites
+// GET /api/kondites?period_id=
 func GetKondites(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	var kondites []models.Kondite
-	if err := db.Preload("Employee").Find(&kondites).Error; err != nil {
+	if err := filterPeriode(db.Preload("Employee"), periode).Find(&kondites).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kondite"})
 		return
 	}
@@ -23,20 +28,28 @@ func GetKondites(c *gin.Context) {
 
 // CreateKondite - POST /api/kondites
 // Membuat kondite baru (SP1, SP2, dsb.).
+// Jika kategori ada di katalog, end_date & min_point yang kosong diisi dari nilai default kategori,
+// dan aturan eskalasi kategori diterapkan (mis. SP1 kedua dalam 6 bulan => SP2).
+// Kondite baru berstatus pending (menunggu persetujuan HR), atau draft jika draft = true.
 func CreateKondite(c *gin.Context) {
 	var input struct {
-		EmployeeID  uint   `json:"employee_id" binding:"required"`
-		Category    string `json:"category" binding:"required"`
-		StartDate   string `json:"start_date" binding:"required"` // Format "YYYY-MM-DD"
-		EndDate     string `json:"end_date" binding:"required"`   // Format "YYYY-MM-DD"
-		Description string `json:"description"`
-		MinPoint    float64  `json:"min_point"`
+		EmployeeID  uint     `json:"employee_id" binding:"required"`
+		Category    string   `json:"category" binding:"required"`
+		StartDate   string   `json:"start_date" binding:"required"` // Format "YYYY-MM-DD"
+		EndDate     string   `json:"end_date"`                      // Format "YYYY-MM-DD", default: start_date + durasi kategori
+		Description string   `json:"description"`
+		MinPoint    *float64 `json:"min_point"` // default: pengurang poin kategori
+		PeriodID    uint     `json:"period_id"`
+		Draft       bool     `json:"draft"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
 
 	// Parse tanggal
 	start, err := time.Parse("2006-01-02", input.StartDate)
@@ -44,19 +57,57 @@ func CreateKondite(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format start_date tidak valid (YYYY-MM-DD)"})
 		return
 	}
-	end, err := time.Parse("2006-01-02", input.EndDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format end_date tidak valid (YYYY-MM-DD)"})
-		return
-	}
 
 	kondite := models.Kondite{
 		EmployeeID:  input.EmployeeID,
 		Category:    input.Category,
 		StartDate:   start,
-		EndDate:     end,
 		Description: input.Description,
-		MinPoint:    input.MinPoint,
+		PeriodID:    input.PeriodID,
+		Status:      models.KonditeStatusPending,
+	}
+	if input.Draft {
+		kondite.Status = models.KonditeStatusDraft
+	}
+
+	// Terapkan default & eskalasi dari katalog kategori
+	category, adaDiKatalog := kategoriKondite(input.Category)
+	if adaDiKatalog {
+		category, err = eskalasiKategori(input.EmployeeID, category, start)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa eskalasi kondite"})
+			return
+		}
+		if category.Code != input.Category {
+			kondite.Category = category.Code
+			kondite.EscalatedFrom = input.Category
+		}
+	}
+
+	if input.EndDate != "" {
+		end, err := time.Parse("2006-01-02", input.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format end_date tidak valid (YYYY-MM-DD)"})
+			return
+		}
+		kondite.EndDate = end
+	} else if adaDiKatalog && category.DefaultDurationDays > 0 {
+		kondite.EndDate = start.AddDate(0, 0, category.DefaultDurationDays-1)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date wajib diisi untuk kategori tanpa durasi default"})
+		return
+	}
+
+	switch {
+	case input.MinPoint != nil:
+		kondite.MinPoint = *input.MinPoint
+	case adaDiKatalog:
+		kondite.MinPoint = category.DefaultMinPoint
+	}
+
+	// Kondite dihitung pada setiap periode yang overlap dengan masa berlakunya
+	if !validasiRentangTidakTerkunci(c, kondite.StartDate, kondite.EndDate) {
+		return
 	}
 
 	if err := db.Create(&kondite).Error; err != nil {
@@ -68,7 +119,7 @@ func CreateKondite(c *gin.Context) {
 }
 
 // UpdateKondite - PUT /api/kondites/:id
-// Memperbarui data kondite tertentu.
+// Memperbarui data kondite tertentu (hanya kondite berstatus draft / pending).
 func UpdateKondite(c *gin.Context) {
 	id := c.Param("id")
 
@@ -79,17 +130,29 @@ func UpdateKondite(c *gin.Context) {
 		return
 	}
 
+	if !validasiKonditeDapatDiubah(c, kondite) {
+		return
+	}
+
 	var input struct {
 		EmployeeID  uint   `json:"employee_id"`
 		Category    string `json:"category"`
 		StartDate   string `json:"start_date"`
 		EndDate     string `json:"end_date"`
 		Description string `json:"description"`
+		PeriodID    uint   `json:"period_id"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Periode lama maupun periode baru tidak boleh terkunci
+	if !validasiPeriodeInput(c, kondite.PeriodID) || !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+	if !validasiRentangTidakTerkunci(c, kondite.StartDate, kondite.EndDate) {
+		return
+	}
 
 	// Jika field kosong, biarkan data lama
 	if input.EmployeeID != 0 {
@@ -113,6 +176,13 @@ func UpdateKondite(c *gin.Context) {
 	if input.Description != "" {
 		kondite.Description = input.Description
 	}
+	if input.PeriodID != 0 {
+		kondite.PeriodID = input.PeriodID
+	}
+	// Masa berlaku baru juga tidak boleh mengenai periode terkunci
+	if !validasiRentangTidakTerkunci(c, kondite.StartDate, kondite.EndDate) {
+		return
+	}
 
 	if err := db.Save(&kondite).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui data kondite"})
@@ -123,7 +193,7 @@ func UpdateKondite(c *gin.Context) {
 }
 
 // DeleteKondite - DELETE /api/kondites/:id
-// Menghapus data kondite.
+// Menghapus data kondite (hanya kondite berstatus draft / pending).
 func DeleteKondite(c *gin.Context) {
 	id := c.Param("id")
 
@@ -132,6 +202,12 @@ func DeleteKondite(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Kondite tidak ditemukan"})
 		return
 	}
+	if !validasiKonditeDapatDiubah(c, kondite) {
+		return
+	}
+	if !validasiPeriodeInput(c, kondite.PeriodID) || !validasiRentangTidakTerkunci(c, kondite.StartDate, kondite.EndDate) {
+		return
+	}
 
 	if err := db.Delete(&kondite).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kondite"})
@@ -140,3 +216,13 @@ func DeleteKondite(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": "Kondite berhasil dihapus"})
 }
+
+// validasiKonditeDapatDiubah => kondite yang sudah diputuskan (active, appealed, upheld, revoked) hanya
+// berubah melalui alur persetujuan / banding. Jika tidak dapat diubah, response 409 langsung dikirim.
+func validasiKonditeDapatDiubah(c *gin.Context, kondite models.Kondite) bool {
+	if kondite.Status != models.KonditeStatusDraft && kondite.Status != models.KonditeStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kondite berstatus " + kondite.Status + " tidak dapat diubah atau dihapus"})
+		return false
+	}
+	return true
+}
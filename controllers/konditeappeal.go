@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusKonditeBerlaku => status kondite yang dihitung sebagai pengurang poin
+var statusKonditeBerlaku = []string{models.KonditeStatusActive, models.KonditeStatusUpheld}
+
+// keputusanKondite => status asal -> keputusan HR -> status tujuan
+var keputusanKondite = map[string]map[string]string{
+	models.KonditeStatusPending: {
+		"approve": models.KonditeStatusActive,
+		"reject":  models.KonditeStatusRevoked,
+	},
+	models.KonditeStatusAppealed: {
+		"uphold": models.KonditeStatusUpheld,
+		"revoke": models.KonditeStatusRevoked,
+	},
+}
+
+// SubmitKondite - POST /api/kondites/:id/submit
+// Mengajukan kondite draft untuk disetujui HR (draft -> pending).
+func SubmitKondite(c *gin.Context) {
+	kondite, ok := ambilKonditeUntukTransisi(c, models.KonditeStatusDraft)
+	if !ok {
+		return
+	}
+
+	kondite.Status = models.KonditeStatusPending
+	if err := db.Save(&kondite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengajukan kondite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": kondite})
+}
+
+// AppealKondite - POST /api/kondites/:id/appeal
+// Pegawai yang bersangkutan mengajukan banding atas kondite aktif (active -> appealed).
+func AppealKondite(c *gin.Context) {
+	kondite, ok := ambilKonditeUntukTransisi(c, models.KonditeStatusActive)
+	if !ok {
+		return
+	}
+	if kondite.EmployeeID != idPenggunaLogin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Banding hanya dapat diajukan oleh pegawai yang bersangkutan"})
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alasan (reason) wajib diisi"})
+		return
+	}
+
+	now := time.Now()
+	kondite.Status = models.KonditeStatusAppealed
+	kondite.AppealReason = input.Reason
+	kondite.AppealedAt = &now
+	if err := db.Save(&kondite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengajukan banding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": kondite})
+}
+
+// DecideKondite - POST /api/kondites/:id/decision (khusus HR / admin)
+// pending: approve => active, reject => revoked
+// appealed: uphold => upheld, revoke => revoked
+func DecideKondite(c *gin.Context) {
+	kondite, ok := ambilKonditeUntukTransisi(c, models.KonditeStatusPending, models.KonditeStatusAppealed)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Decision string `json:"decision" binding:"required"`
+		Note     string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tujuan, ok := keputusanKondite[kondite.Status][input.Decision]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Keputusan " + input.Decision + " tidak berlaku untuk kondite berstatus " + kondite.Status})
+		return
+	}
+
+	now := time.Now()
+	kondite.Status = tujuan
+	kondite.DecidedBy = idPenggunaLogin(c)
+	kondite.DecisionNote = input.Note
+	kondite.DecidedAt = &now
+	if err := db.Save(&kondite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan keputusan kondite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": kondite})
+}
+
+// ambilKonditeUntukTransisi mengambil kondite dari :id dan memastikan statusnya termasuk statusAsal
+// serta periodenya (maupun periode yang overlap dengan masa berlakunya) belum terkunci. Jika tidak, response error langsung dikirim dan ok = false.
+func ambilKonditeUntukTransisi(c *gin.Context, statusAsal ...string) (models.Kondite, bool) {
+	var kondite models.Kondite
+	if err := db.First(&kondite, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kondite tidak ditemukan"})
+		return kondite, false
+	}
+	if !statusTermasuk(kondite.Status, statusAsal) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transisi tidak diizinkan untuk kondite berstatus " + kondite.Status})
+		return kondite, false
+	}
+	if !validasiPeriodeInput(c, kondite.PeriodID) || !validasiRentangTidakTerkunci(c, kondite.StartDate, kondite.EndDate) {
+		return kondite, false
+	}
+	return kondite, true
+}
+
+// statusTermasuk => true jika status ada pada daftar
+func statusTermasuk(status string, daftar []string) bool {
+	for _, s := range daftar {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// KonditeCategoryInput adalah payload untuk pembuatan / update kategori kondite
+type KonditeCategoryInput struct {
+	Code                   string  `json:"code" binding:"required"`
+	Name                   string  `json:"name" binding:"required"`
+	DefaultMinPoint        float64 `json:"default_min_point"`
+	DefaultDurationDays    int     `json:"default_duration_days"`
+	EscalateTo             string  `json:"escalate_to"`
+	EscalationCount        int     `json:"escalation_count"`
+	EscalationWindowMonths int     `json:"escalation_window_months"`
+}
+
+// GetKonditeCategories - GET /api/kondite-categories
+func GetKonditeCategories(c *gin.Context) {
+	var categories []models.KonditeCategory
+	if err := db.Order("default_min_point asc").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori kondite"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// CreateKonditeCategory - POST /api/kondite-categories
+func CreateKonditeCategory(c *gin.Context) {
+	var input KonditeCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var category models.KonditeCategory
+	if !isiKategoriKondite(c, &category, input) {
+		return
+	}
+
+	if err := db.Create(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code kategori kondite " + category.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kategori kondite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// UpdateKonditeCategory - PUT /api/kondite-categories/:id
+// Code hanya dapat diganti selama kategori belum direferensikan (409).
+func UpdateKonditeCategory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var category models.KonditeCategory
+	if err := db.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kategori kondite tidak ditemukan"})
+		return
+	}
+
+	var input KonditeCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Code direferensikan kondite & aturan eskalasi, jadi hanya boleh diganti selama belum dipakai
+	if input.Code != category.Code && !validasiKategoriKonditeTidakDipakai(c, category.Code) {
+		return
+	}
+	if !isiKategoriKondite(c, &category, input) {
+		return
+	}
+
+	if err := db.Save(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code kategori kondite " + category.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kategori kondite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// DeleteKonditeCategory - DELETE /api/kondite-categories/:id
+func DeleteKonditeCategory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var category models.KonditeCategory
+	if err := db.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kategori kondite tidak ditemukan"})
+		return
+	}
+
+	// Kategori dihapus permanen (agar code bisa dipakai ulang), jadi tolak selama masih direferensikan
+	if !validasiKategoriKonditeTidakDipakai(c, category.Code) {
+		return
+	}
+
+	if err := db.Unscoped().Delete(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kategori kondite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// validasiKategoriKonditeTidakDipakai memastikan code kategori tidak direferensikan kondite maupun
+// aturan eskalasi kategori lain. Jika masih dipakai, response 409 langsung dikirim dan mengembalikan false.
+func validasiKategoriKonditeTidakDipakai(c *gin.Context, code string) bool {
+	var jumlahKondite, jumlahEskalasi int64
+	if err := db.Model(&models.Kondite{}).Where("category = ?", code).Count(&jumlahKondite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pemakaian kategori kondite"})
+		return false
+	}
+	if err := db.Model(&models.KonditeCategory{}).Where("escalate_to = ?", code).Count(&jumlahEskalasi).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pemakaian kategori kondite"})
+		return false
+	}
+	if jumlahKondite > 0 || jumlahEskalasi > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kategori kondite " + code + " masih dipakai oleh kondite atau aturan eskalasi"})
+		return false
+	}
+	return true
+}
+
+// isiKategoriKondite memvalidasi input lalu mengisi field kategori kondite.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiKategoriKondite(c *gin.Context, category *models.KonditeCategory, input KonditeCategoryInput) bool {
+	if input.DefaultMinPoint < 0 || input.DefaultDurationDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default_min_point dan default_duration_days tidak boleh negatif"})
+		return false
+	}
+	if input.EscalateTo != "" {
+		if input.EscalateTo == input.Code {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "escalate_to tidak boleh sama dengan code"})
+			return false
+		}
+		if _, ok := kategoriKondite(input.EscalateTo); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori eskalasi " + input.EscalateTo + " tidak ditemukan"})
+			return false
+		}
+		if input.EscalationCount < 2 || input.EscalationWindowMonths < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "escalation_count minimal 2 dan escalation_window_months minimal 1"})
+			return false
+		}
+	}
+
+	category.Code = input.Code
+	category.Name = input.Name
+	category.DefaultMinPoint = input.DefaultMinPoint
+	category.DefaultDurationDays = input.DefaultDurationDays
+	category.EscalateTo = input.EscalateTo
+	category.EscalationCount = input.EscalationCount
+	category.EscalationWindowMonths = input.EscalationWindowMonths
+	return true
+}
+
+// kategoriKondite => kategori pada katalog berdasarkan code (ok = false jika tidak ada)
+func kategoriKondite(code string) (models.KonditeCategory, bool) {
+	var category models.KonditeCategory
+	if err := db.Where("code = ?", code).First(&category).Error; err != nil {
+		return category, false
+	}
+	return category, true
+}
+
+// eskalasiKategori menerapkan aturan eskalasi: jika pegawai sudah memiliki kondite dengan kategori
+// yang sama (active / upheld) sebanyak (EscalationCount - 1) dalam jendela waktu sebelum tanggal mulai, kategori
+// dinaikkan ke EscalateTo. Eskalasi dapat berantai (mis. SP1 -> SP2 -> SP3).
+func eskalasiKategori(empID uint, category models.KonditeCategory, start time.Time) (models.KonditeCategory, error) {
+	dikunjungi := map[string]bool{}
+	for category.EscalateTo != "" && !dikunjungi[category.Code] {
+		dikunjungi[category.Code] = true
+
+		var jumlah int64
+		batasAwal := start.AddDate(0, -category.EscalationWindowMonths, 0)
+		err := db.Model(&models.Kondite{}).
+			Where("employee_id = ? AND category = ? AND start_date >= ? AND start_date <= ?", empID, category.Code, batasAwal, start).
+			Where("status IN ?", statusKonditeBerlaku).
+			Count(&jumlah).Error
+		if err != nil {
+			return category, err
+		}
+		if int(jumlah)+1 < category.EscalationCount {
+			break
+		}
+
+		berikut, ok := kategoriKondite(category.EscalateTo)
+		if !ok {
+			break
+		}
+		category = berikut
+	}
+	return category, nil
+}
@@ -9,11 +9,33 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-// GET /api/kpis
-// Ambil semua KPI
+// Struktur input (payload) penugasan KPI
+type KPIInput struct {
+    TemplateID          uint     `json:"template_id" binding:"required"`
+    EmployeeID          uint     `json:"employee_id" binding:"required"`
+    PeriodID            uint     `json:"period_id"`
+    WeightOverride      *float64 `json:"weight_override"`
+    TargetOverride      *string  `json:"target_override"`
+    TargetValueOverride *float64 `json:"target_value_override"`
+    Score               float64  `json:"score"`
+    Validated           bool     `json:"validated"`
+}
+
+// GET /api/kpis?period_id=&employee_id=
+// Ambil semua penugasan KPI beserta templatenya (opsional: filter per periode / pegawai)
 func GetKPIs(c *gin.Context) {
+    periode, ok := periodeDariQuery(c)
+    if !ok {
+        return
+    }
+
+    query := filterPeriode(db.Preload("Template"), periode)
+    if empID := c.Query("employee_id"); empID != "" {
+        query = query.Where("employee_id = ?", empID)
+    }
+
     var kpis []models.KPI
-    if err := db.Find(&kpis).Error; err != nil {
+    if err := query.Find(&kpis).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -21,11 +43,11 @@ func GetKPIs(c *gin.Context) {
 }
 
 // GET /api/kpis/:id
-// Ambil satu KPI berdasar ID
+// Ambil satu penugasan KPI berdasar ID
 func GetKPIByID(c *gin.Context) {
     id, _ := strconv.Atoi(c.Param("id"))
     var kpi models.KPI
-    if err := db.First(&kpi, id).Error; err != nil {
+    if err := db.Preload("Template").First(&kpi, id).Error; err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "KPI tidak ditemukan"})
         return
     }
@@ -33,23 +55,34 @@ func GetKPIByID(c *gin.Context) {
 }
 
 // POST /api/kpis
-// Buat KPI baru
+// Tugaskan template KPI ke seorang pegawai
 func CreateKPI(c *gin.Context) {
-    var input models.KPI
+    var input KPIInput
     if err := c.ShouldBindJSON(&input); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
         return
     }
+    if !validasiPeriodeInput(c, input.PeriodID) {
+        return
+    }
+
+    var kpi models.KPI
+    if !isiKPI(c, &kpi, input) {
+        return
+    }
+    if kpi.Validated && !validasiSheetKPI(c, kpi) {
+        return
+    }
 
-    if err := db.Create(&input).Error; err != nil {
+    if err := db.Omit("Template").Create(&kpi).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
-    c.JSON(http.StatusOK, gin.H{"data": input})
+    c.JSON(http.StatusOK, gin.H{"data": kpi})
 }
 
 // PUT /api/kpis/:id
-// Update KPI yang sudah ada
+// Update penugasan KPI yang sudah ada
 func UpdateKPI(c *gin.Context) {
     id, _ := strconv.Atoi(c.Param("id"))
     var kpi models.KPI
@@ -58,27 +91,24 @@ func UpdateKPI(c *gin.Context) {
         return
     }
 
-    var input models.KPI
+    var input KPIInput
     if err := c.ShouldBindJSON(&input); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
         return
     }
+    // Periode lama maupun periode baru tidak boleh terkunci
+    if !validasiPeriodeInput(c, kpi.PeriodID) || !validasiPeriodeInput(c, input.PeriodID) {
+        return
+    }
 
-    // Update field sesuai input
-    kpi.Title       = input.Title
-    kpi.Category    = input.Category
-    kpi.Weight      = input.Weight
-    kpi.Target      = input.Target
-    kpi.Poor        = input.Poor
-    kpi.Fair        = input.Fair
-    kpi.Good        = input.Good
-    kpi.Outstanding = input.Outstanding
-    kpi.Exceptional = input.Exceptional
-    kpi.Score       = input.Score
-    kpi.Validated   = input.Validated
-    kpi.EmployeeID  = input.EmployeeID
+    if !isiKPI(c, &kpi, input) {
+        return
+    }
+    if kpi.Validated && !validasiSheetKPI(c, kpi) {
+        return
+    }
 
-    if err := db.Save(&kpi).Error; err != nil {
+    if err := db.Omit("Template").Save(&kpi).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -86,7 +116,7 @@ func UpdateKPI(c *gin.Context) {
 }
 
 // DELETE /api/kpis/:id
-// Hapus KPI berdasarkan ID
+// Hapus penugasan KPI berdasarkan ID
 func DeleteKPI(c *gin.Context) {
     id, _ := strconv.Atoi(c.Param("id"))
     var kpi models.KPI
@@ -94,7 +124,90 @@ func DeleteKPI(c *gin.Context) {
         c.JSON(http.StatusNotFound, gin.H{"error": "KPI tidak ditemukan"})
         return
     }
+    if !validasiPeriodeInput(c, kpi.PeriodID) {
+        return
+    }
 
     db.Delete(&kpi)
     c.JSON(http.StatusOK, gin.H{"data": true})
 }
+
+// isiKPI memastikan template ada lalu mengisi field penugasan KPI (Template ikut terisi untuk response).
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiKPI(c *gin.Context, kpi *models.KPI, input KPIInput) bool {
+    var template models.KPITemplate
+    if err := db.First(&template, input.TemplateID).Error; err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Template KPI tidak ditemukan"})
+        return false
+    }
+    if input.WeightOverride != nil && (*input.WeightOverride < 0 || *input.WeightOverride > 100) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "weight_override harus di antara 0 dan 100"})
+        return false
+    }
+
+    // Total bobot KPI pegawai pada periode tidak boleh melebihi 100
+    bobot := template.Weight
+    if input.WeightOverride != nil {
+        bobot = *input.WeightOverride
+    }
+    if err := validasiBobotKPI(input.EmployeeID, input.PeriodID, kpi.ID, bobot); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return false
+    }
+
+    kpi.TemplateID          = template.ID
+    kpi.Template            = template
+    kpi.EmployeeID          = input.EmployeeID
+    kpi.PeriodID            = input.PeriodID
+    kpi.WeightOverride      = input.WeightOverride
+    kpi.TargetOverride      = input.TargetOverride
+    kpi.TargetValueOverride = input.TargetValueOverride
+    kpi.Score               = input.Score
+    kpi.Validated           = input.Validated
+
+    // KPI yang sudah memiliki nilai aktual: skor dihitung ulang dari rubrik, bukan dari input
+    if kpi.Actual != nil {
+        skor, err := HitungSkorRubrik(template, *kpi.Actual, targetNumerikKPI(*kpi))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return false
+        }
+        kpi.Score = skor
+    }
+    return true
+}
+
+// validasiSheetKPI => KPI hanya boleh divalidasi jika sheet KPI pegawai (dengan perubahan kpi
+// yang belum disimpan) sudah sesuai. Jika tidak, response error langsung dikirim dan mengembalikan false.
+func validasiSheetKPI(c *gin.Context, kpi models.KPI) bool {
+    periode, ok := ambilPeriode(c, kpi.PeriodID)
+    if !ok {
+        return false
+    }
+    sheet, err := sheetKPISetelahPerubahan(kpi, periode)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return false
+    }
+    if !sheet.Valid {
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Sheet KPI belum valid", "data": sheet})
+        return false
+    }
+    return true
+}
+
+// bobotKPI => bobot efektif penugasan KPI (override atau bobot template)
+func bobotKPI(kpi models.KPI) float64 {
+    if kpi.WeightOverride != nil {
+        return *kpi.WeightOverride
+    }
+    return kpi.Template.Weight
+}
+
+// targetKPI => target efektif penugasan KPI (override atau target template)
+func targetKPI(kpi models.KPI) string {
+    if kpi.TargetOverride != nil {
+        return *kpi.TargetOverride
+    }
+    return kpi.Template.Target
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -10,8 +12,9 @@ import (
 
 // KpiCategoryInput adalah payload untuk input pembuatan / update kategori KPI
 type KpiCategoryInput struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name         string   `json:"name" binding:"required"`
+	Description  string   `json:"description"`
+	TargetWeight *float64 `json:"target_weight"`
 }
 
 // CreateKpiCategory - POST /api/kpi-categories
@@ -21,10 +24,14 @@ func CreateKpiCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !validasiKategoriKPI(c, input, 0) {
+		return
+	}
 
 	category := models.KpiCategory{
-		Name:        input.Name,
-		Description: input.Description,
+		Name:         input.Name,
+		Description:  input.Description,
+		TargetWeight: input.TargetWeight,
 	}
 
 	if err := db.Create(&category).Error; err != nil {
@@ -60,9 +67,13 @@ func UpdateKpiCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !validasiKategoriKPI(c, input, category.ID) {
+		return
+	}
 
 	category.Name = input.Name
 	category.Description = input.Description
+	category.TargetWeight = input.TargetWeight
 
 	if err := db.Save(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kategori KPI"})
@@ -89,3 +100,67 @@ func DeleteKpiCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": true})
 }
+
+// validasiKategoriKPI memastikan target_weight (jika diisi) di antara 0 dan 100, nama kategori sama dengan
+// kategori template KPI yang dibandingkan pada sheet, tidak ada kategori lain (selain kecuali) dengan target
+// untuk nama yang sama, dan total target seluruh kategori tidak melebihi TotalBobotKPI (tepat TotalBobotKPI
+// jika semua kategori template sudah memiliki target).
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func validasiKategoriKPI(c *gin.Context, input KpiCategoryInput, kecuali uint) bool {
+	if input.TargetWeight == nil {
+		return true
+	}
+	if *input.TargetWeight < 0 || *input.TargetWeight > TotalBobotKPI {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_weight harus di antara 0 dan 100"})
+		return false
+	}
+
+	nama := normalisasiKategoriKPI(input.Name)
+	dikenal := map[string]bool{}
+	for _, k := range KategoriTemplateKPI {
+		dikenal[k] = true
+	}
+	var kategoriTemplate []string
+	if err := db.Model(&models.KPITemplate{}).Distinct().Pluck("category", &kategoriTemplate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa kategori template KPI"})
+		return false
+	}
+	for _, k := range kategoriTemplate {
+		dikenal[normalisasiKategoriKPI(k)] = true
+	}
+	if !dikenal[nama] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori " + input.Name + " tidak dipakai template KPI, target_weight tidak dapat dibandingkan pada sheet"})
+		return false
+	}
+
+	var lain []models.KpiCategory
+	if err := db.Where("target_weight IS NOT NULL AND id <> ?", kecuali).Find(&lain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa target bobot kategori KPI"})
+		return false
+	}
+	total := *input.TargetWeight
+	bertarget := map[string]bool{nama: true}
+	for _, k := range lain {
+		if normalisasiKategoriKPI(k.Name) == nama {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori " + nama + " sudah memiliki target_weight"})
+			return false
+		}
+		bertarget[normalisasiKategoriKPI(k.Name)] = true
+		total += *k.TargetWeight
+	}
+	if total > TotalBobotKPI+1e-6 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Total target_weight seluruh kategori menjadi %.2f, maksimal %.0f", total, TotalBobotKPI)})
+		return false
+	}
+
+	// Jika semua kategori template sudah memiliki target, totalnya harus tepat 100 agar sheet bisa valid
+	lengkap := true
+	for nama := range dikenal {
+		lengkap = lengkap && bertarget[nama]
+	}
+	if lengkap && math.Abs(total-TotalBobotKPI) > 1e-6 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Total target_weight seluruh kategori %.2f, seharusnya %.0f", total, TotalBobotKPI)})
+		return false
+	}
+	return true
+}
@@ -12,21 +12,7 @@ type KPIEvaluationInput struct {
 	EmployeeID  uint   `json:"employee_id" binding:"required"`
 	KPIID       uint   `json:"kpi_id" binding:"required"`
 	Achievement string `json:"achievement" binding:"required"`
-}
-
-// Buat daftar tingkatan penilaian => "poor 1", "fair 2", dsb.
-var AchievementList = []string{
-	"poor 1",
-	"fair 2",
-	"good 3",
-	"outstanding 4",
-	"exceptional 5",
-}
-
-// GetKPIAchievementList - GET /api/kpi_achievement_list
-// Mengembalikan daftar tingkatan penilaian untuk front-end
-func GetKPIAchievementList(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"data": AchievementList})
+	PeriodID    uint   `json:"period_id"` // opsional, harus sama dengan periode KPI
 }
 
 // CreateKPIEvaluation - POST /api/kpi_evaluations
@@ -38,14 +24,45 @@ func CreateKPIEvaluation(c *gin.Context) {
 		return
 	}
 
-	// Tentukan point berdasarkan achievement
-	point := parseAchievementToPoint(input.Achievement)
+	// KPI harus ada dan milik pegawai yang dinilai
+	var kpi models.KPI
+	if err := db.First(&kpi, input.KPIID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "KPI tidak ditemukan"})
+		return
+	}
+	if kpi.EmployeeID != input.EmployeeID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "KPI bukan milik pegawai tersebut"})
+		return
+	}
+
+	// Periode selalu mengikuti KPI (tidak boleh menempelkan penilaian ke KPI periode lain)
+	if input.PeriodID != 0 && input.PeriodID != kpi.PeriodID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_id tidak sesuai dengan periode KPI"})
+		return
+	}
+	input.PeriodID = kpi.PeriodID
+	if !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+
+	// Tentukan point berdasarkan achievement (tabel tingkatan capaian)
+	point, ok, valid, err := poinCapaian(input.Achievement)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tingkatan capaian"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Achievement tidak dikenal", "valid_codes": valid})
+		return
+	}
 
 	kpiev := models.KPIEvaluation{
 		EmployeeID:  input.EmployeeID,
 		KPIID:       input.KPIID,
 		Achievement: input.Achievement, // simpan teks aslinya
 		Point:       point,            // simpan nilai numeriknya
+		PeriodID:    input.PeriodID,
+		Status:      models.KPIEvaluationStatusPending, // menunggu persetujuan atasan
 	}
 
 	if err := db.Create(&kpiev).Error; err != nil {
@@ -56,29 +73,16 @@ func CreateKPIEvaluation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": kpiev})
 }
 
-// parseAchievementToPoint mengubah "poor 1" -> 1, "fair 2" -> 2, dst.
-func parseAchievementToPoint(ach string) float64 {
-	switch ach {
-	case "poor 1":
-		return 1
-	case "fair 2":
-		return 2
-	case "good 3":
-		return 3
-	case "outstanding 4":
-		return 4
-	case "exceptional 5":
-		return 5
-	default:
-		return 0
-	}
-}
-
-// GetAllKPIEvaluations - GET /api/kpi_evaluations
+// GetAllKPIEvaluations - GET /api/kpi_evaluations?period_id=
 // Mengambil semua data penilaian KPI
 func GetAllKPIEvaluations(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	var evaluations []models.KPIEvaluation
-	if err := db.Find(&evaluations).Error; err != nil {
+	if err := filterPeriode(db, periode).Find(&evaluations).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data penilaian KPI"})
 		return
 	}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SumberSkorKPI => asal skor satu KPI yang dipakai pada kalibrasi
+type SumberSkorKPI struct {
+	KPIID         uint    `json:"kpi_id"`
+	Title         string  `json:"title"`
+	Score         float64 `json:"score"`
+	Sumber        string  `json:"sumber"`                   // "evaluation" atau "kpi" (skor manual / rubrik)
+	Aturan        string  `json:"aturan,omitempty"`         // aturan agregasi periode (hanya sumber evaluation)
+	EvaluationIDs []uint  `json:"evaluation_ids,omitempty"` // evaluasi yang membentuk skor
+}
+
+// DecideKPIEvaluation - POST /api/kpi_evaluations/:id/decision (khusus atasan / HR / admin)
+// pending: approve => approved, reject => rejected. Pegawai tidak boleh memutuskan penilaiannya sendiri,
+// dan atasan hanya boleh memutuskan penilaian bawahan langsungnya (Employee.ManagerID).
+func DecideKPIEvaluation(c *gin.Context) {
+	var evaluation models.KPIEvaluation
+	if err := db.First(&evaluation, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penilaian KPI tidak ditemukan"})
+		return
+	}
+	if evaluation.Status != models.KPIEvaluationStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penilaian berstatus " + evaluation.Status + " tidak dapat diputuskan lagi"})
+		return
+	}
+	if !validasiPeriodeInput(c, evaluation.PeriodID) {
+		return
+	}
+
+	penyetuju := idPenggunaLogin(c)
+	if penyetuju == evaluation.EmployeeID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Pegawai tidak dapat memutuskan penilaian KPI miliknya sendiri"})
+		return
+	}
+	// Atasan hanya boleh memutuskan penilaian bawahan langsungnya (HR / admin bebas)
+	if role, _ := c.Get("role"); role == models.RoleManager {
+		var pegawai models.Employee
+		if err := db.First(&pegawai, evaluation.EmployeeID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pegawai yang dinilai tidak ditemukan"})
+			return
+		}
+		if pegawai.ManagerID == nil || *pegawai.ManagerID != penyetuju {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Atasan hanya dapat memutuskan penilaian KPI bawahan langsungnya"})
+			return
+		}
+	}
+
+	var input struct {
+		Decision string `json:"decision" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch input.Decision {
+	case "approve":
+		evaluation.Status = models.KPIEvaluationStatusApproved
+	case "reject":
+		evaluation.Status = models.KPIEvaluationStatusRejected
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision harus approve atau reject"})
+		return
+	}
+	now := time.Now()
+	evaluation.ApprovedBy = penyetuju
+	evaluation.ApprovedAt = &now
+
+	if err := db.Save(&evaluation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan keputusan penilaian KPI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": evaluation})
+}
+
+// TerapkanEvaluasiKPI mengganti Score setiap KPI dengan hasil agregasi evaluasi approved-nya
+// (aturan agregasi dari periode: latest / average / max / min). KPI tanpa evaluasi approved
+// tetap memakai Score tersimpan. Mengembalikan asal skor setiap KPI.
+func TerapkanEvaluasiKPI(kpis []models.KPI, periode *models.EvaluationPeriod) ([]SumberSkorKPI, error) {
+	sumber := []SumberSkorKPI{}
+	if len(kpis) == 0 {
+		return sumber, nil
+	}
+
+	ids := make([]uint, len(kpis))
+	for i, k := range kpis {
+		ids[i] = k.ID
+	}
+	var evaluations []models.KPIEvaluation
+	if err := db.Where("kpi_id IN ? AND status = ?", ids, models.KPIEvaluationStatusApproved).
+		Order("approved_at asc, id asc").Find(&evaluations).Error; err != nil {
+		return nil, err
+	}
+	perKPI := map[uint][]models.KPIEvaluation{}
+	for _, e := range evaluations {
+		perKPI[e.KPIID] = append(perKPI[e.KPIID], e)
+	}
+
+	aturan := aturanEvaluasiPeriode(periode)
+	for i := range kpis {
+		item := SumberSkorKPI{KPIID: kpis[i].ID, Title: kpis[i].Template.Title, Score: kpis[i].Score, Sumber: "kpi"}
+		if evs := perKPI[kpis[i].ID]; len(evs) > 0 {
+			skor, dipakai := agregasiEvaluasi(evs, aturan)
+			kpis[i].Score = skor
+			item.Score = skor
+			item.Sumber = "evaluation"
+			item.Aturan = aturan
+			item.EvaluationIDs = dipakai
+		}
+		sumber = append(sumber, item)
+	}
+	return sumber, nil
+}
+
+// agregasiEvaluasi => skor hasil agregasi & ID evaluasi yang membentuknya. evs terurut dari approved_at terlama.
+func agregasiEvaluasi(evs []models.KPIEvaluation, aturan string) (float64, []uint) {
+	switch aturan {
+	case models.KPIEvaluationRuleAverage:
+		var total float64
+		ids := []uint{}
+		for _, e := range evs {
+			total += e.Point
+			ids = append(ids, e.ID)
+		}
+		return total / float64(len(evs)), ids
+	case models.KPIEvaluationRuleMax, models.KPIEvaluationRuleMin:
+		pilih := evs[0]
+		for _, e := range evs[1:] {
+			if (aturan == models.KPIEvaluationRuleMax && e.Point > pilih.Point) ||
+				(aturan == models.KPIEvaluationRuleMin && e.Point < pilih.Point) {
+				pilih = e
+			}
+		}
+		return pilih.Point, []uint{pilih.ID}
+	default:
+		terakhir := evs[len(evs)-1]
+		return terakhir.Point, []uint{terakhir.ID}
+	}
+}
+
+// aturanEvaluasiPeriode => aturan agregasi evaluasi KPI periode (default latest)
+func aturanEvaluasiPeriode(periode *models.EvaluationPeriod) string {
+	if periode == nil || periode.KPIEvaluationRule == "" {
+		return models.KPIEvaluationRuleLatest
+	}
+	return periode.KPIEvaluationRule
+}
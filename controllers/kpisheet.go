@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TotalBobotKPI => total bobot KPI satu pegawai dalam satu periode harus 100 (persen)
+const TotalBobotKPI = 100.0
+
+// ItemSheetKPI => satu penugasan KPI pada sheet beserta bobot & target efektifnya
+type ItemSheetKPI struct {
+	KPIID     uint    `json:"kpi_id"`
+	Title     string  `json:"title"`
+	Category  string  `json:"category"`
+	Weight    float64 `json:"weight"`
+	Target    string  `json:"target"`
+	Score     float64 `json:"score"`
+	Validated bool    `json:"validated"`
+}
+
+// BobotKategoriSheet => total bobot satu kategori dibandingkan target bobot kategori
+type BobotKategoriSheet struct {
+	Category    string   `json:"category"`
+	TotalBobot  float64  `json:"total_bobot"`
+	TargetBobot *float64 `json:"target_bobot"` // nil => kategori tanpa target
+	Sesuai      bool     `json:"sesuai"`
+}
+
+// SheetKPI => ringkasan KPI satu pegawai pada satu periode
+type SheetKPI struct {
+	EmployeeID uint                 `json:"employee_id"`
+	PeriodID   uint                 `json:"period_id"`
+	Items      []ItemSheetKPI       `json:"items"`
+	Kategori   []BobotKategoriSheet `json:"kategori"`
+	TotalBobot float64              `json:"total_bobot"`
+	Valid      bool                 `json:"valid"`
+	Masalah    []string             `json:"masalah"`
+}
+
+// GetKPISheet - GET /api/employees/:id/kpi-sheet?period_id=
+func GetKPISheet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	sheet, err := SusunSheetKPI(uint(id), periode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun sheet KPI"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sheet})
+}
+
+// ValidateKPISheet - POST /api/employees/:id/kpi-sheet/validate?period_id=
+// Menandai semua KPI pegawai pada periode sebagai tervalidasi. period_id wajib diisi,
+// ditolak (422) jika bobot sheet tidak sesuai.
+func ValidateKPISheet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+	if periode == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period_id wajib diisi"})
+		return
+	}
+	if !validasiPeriodeInput(c, periode.ID) {
+		return
+	}
+
+	sheet, err := SusunSheetKPI(uint(id), periode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun sheet KPI"})
+		return
+	}
+	if !sheet.Valid {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Sheet KPI belum valid", "data": sheet})
+		return
+	}
+
+	if err := filterPeriode(db.Model(&models.KPI{}).Where("employee_id = ?", id), periode).
+		Update("validated", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memvalidasi sheet KPI"})
+		return
+	}
+	for i := range sheet.Items {
+		sheet.Items[i].Validated = true
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sheet})
+}
+
+// SusunSheetKPI menyusun sheet KPI pegawai: total bobot per kategori dibandingkan target bobot
+// kategori (KpiCategory.TargetWeight) dan total bobot keseluruhan dibandingkan TotalBobotKPI.
+func SusunSheetKPI(empID uint, periode *models.EvaluationPeriod) (SheetKPI, error) {
+	kpis, err := ambilKPIPegawai(empID, periode)
+	if err != nil {
+		return SheetKPI{}, err
+	}
+	return susunSheetDariKPI(empID, periode, kpis)
+}
+
+// sheetKPISetelahPerubahan menyusun sheet KPI pegawai pada periode seolah perubahan kpi
+// (penugasan baru atau yang diubah, Template sudah terisi) sudah disimpan.
+func sheetKPISetelahPerubahan(kpi models.KPI, periode *models.EvaluationPeriod) (SheetKPI, error) {
+	tersimpan, err := ambilKPIPegawai(kpi.EmployeeID, periode)
+	if err != nil {
+		return SheetKPI{}, err
+	}
+	kpis := []models.KPI{}
+	for _, k := range tersimpan {
+		if k.ID != kpi.ID {
+			kpis = append(kpis, k)
+		}
+	}
+	kpis = append(kpis, kpi)
+	return susunSheetDariKPI(kpi.EmployeeID, periode, kpis)
+}
+
+// susunSheetDariKPI => isi SusunSheetKPI untuk daftar penugasan KPI yang sudah diambil
+func susunSheetDariKPI(empID uint, periode *models.EvaluationPeriod, kpis []models.KPI) (SheetKPI, error) {
+	sheet := SheetKPI{EmployeeID: empID, Items: []ItemSheetKPI{}, Kategori: []BobotKategoriSheet{}, Masalah: []string{}}
+	if periode != nil {
+		sheet.PeriodID = periode.ID
+	}
+
+	target, err := targetBobotKategori()
+	if err != nil {
+		return sheet, err
+	}
+
+	totalKategori := map[string]float64{}
+	for _, k := range kpis {
+		kategori := normalisasiKategoriKPI(k.Template.Category)
+		bobot := bobotKPI(k)
+		sheet.Items = append(sheet.Items, ItemSheetKPI{
+			KPIID:     k.ID,
+			Title:     k.Template.Title,
+			Category:  kategori,
+			Weight:    bobot,
+			Target:    targetKPI(k),
+			Score:     k.Score,
+			Validated: k.Validated,
+		})
+		totalKategori[kategori] += bobot
+		sheet.TotalBobot += bobot
+	}
+
+	// Kategori yang dipakai atau memiliki target
+	var namaKategori []string
+	for nama := range totalKategori {
+		namaKategori = append(namaKategori, nama)
+	}
+	for nama := range target {
+		if _, ok := totalKategori[nama]; !ok {
+			namaKategori = append(namaKategori, nama)
+		}
+	}
+	sort.Strings(namaKategori)
+
+	for _, nama := range namaKategori {
+		item := BobotKategoriSheet{Category: nama, TotalBobot: RoundFloat(totalKategori[nama], 2), Sesuai: true}
+		if t, ok := target[nama]; ok {
+			item.TargetBobot = floatPtr(t)
+			item.Sesuai = math.Abs(totalKategori[nama]-t) < 1e-6
+			if !item.Sesuai {
+				sheet.Masalah = append(sheet.Masalah, fmt.Sprintf("Total bobot kategori %s %.2f, seharusnya %.2f", nama, totalKategori[nama], t))
+			}
+		}
+		sheet.Kategori = append(sheet.Kategori, item)
+	}
+
+	if math.Abs(sheet.TotalBobot-TotalBobotKPI) > 1e-6 {
+		sheet.Masalah = append(sheet.Masalah, fmt.Sprintf("Total bobot KPI %.2f, seharusnya %.0f", sheet.TotalBobot, TotalBobotKPI))
+	}
+	sheet.TotalBobot = RoundFloat(sheet.TotalBobot, 2)
+	sheet.Valid = len(kpis) > 0 && len(sheet.Masalah) == 0
+	if len(kpis) == 0 {
+		sheet.Masalah = append(sheet.Masalah, "Pegawai belum memiliki KPI")
+	}
+	return sheet, nil
+}
+
+// validasiBobotKPI memastikan total bobot KPI pegawai pada periode (termasuk bobot baru, tanpa KPI
+// dengan ID kecuali) tidak melebihi TotalBobotKPI.
+func validasiBobotKPI(empID, periodID, kecuali uint, bobotBaru float64) error {
+	var kpis []models.KPI
+	err := db.Preload("Template").
+		Where("employee_id = ? AND period_id = ? AND id <> ?", empID, periodID, kecuali).
+		Find(&kpis).Error
+	if err != nil {
+		return err
+	}
+
+	total := bobotBaru
+	for _, k := range kpis {
+		total += bobotKPI(k)
+	}
+	if total > TotalBobotKPI+1e-6 {
+		return fmt.Errorf("total bobot KPI pegawai menjadi %.2f, maksimal %.0f", total, TotalBobotKPI)
+	}
+	return nil
+}
+
+// sheetKPITidakValid => sheet KPI yang tidak valid dari semua pegawai yang memiliki KPI pada periode
+func sheetKPITidakValid(periode *models.EvaluationPeriod) ([]SheetKPI, error) {
+	var empIDs []uint
+	if err := filterPeriode(db.Model(&models.KPI{}), periode).Distinct().Pluck("employee_id", &empIDs).Error; err != nil {
+		return nil, err
+	}
+
+	tidakValid := []SheetKPI{}
+	for _, empID := range empIDs {
+		sheet, err := SusunSheetKPI(empID, periode)
+		if err != nil {
+			return nil, err
+		}
+		if !sheet.Valid {
+			tidakValid = append(tidakValid, sheet)
+		}
+	}
+	return tidakValid, nil
+}
+
+// targetBobotKategori => nama kategori (dinormalisasi) => target bobot dari katalog kategori KPI
+func targetBobotKategori() (map[string]float64, error) {
+	var categories []models.KpiCategory
+	if err := db.Where("target_weight IS NOT NULL").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	target := map[string]float64{}
+	for _, k := range categories {
+		target[normalisasiKategoriKPI(k.Name)] = *k.TargetWeight
+	}
+	return target, nil
+}
+
+// KategoriTemplateKPI => kategori template KPI yang dihitung pada kalibrasi (setelah normalisasi)
+var KategoriTemplateKPI = []string{"Perusahaan", "Departemen", "Individu"}
+
+// normalisasiKategoriKPI => "Dept" disamakan dengan "Departemen"
+func normalisasiKategoriKPI(kategori string) string {
+	if kategori == "Dept" {
+		return "Departemen"
+	}
+	return kategori
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// KPITemplateInput adalah payload untuk pembuatan / update template KPI
+type KPITemplateInput struct {
+	Title       string  `json:"title" binding:"required"`
+	Category    string  `json:"category" binding:"required"`
+	Weight      float64 `json:"weight"`
+	Target      string  `json:"target"`
+	Poor        string  `json:"poor"`
+	Fair        string  `json:"fair"`
+	Good        string  `json:"good"`
+	Outstanding string  `json:"outstanding"`
+	Exceptional string  `json:"exceptional"`
+
+	RubricMode           string   `json:"rubric_mode"`
+	TargetValue          *float64 `json:"target_value"`
+	FairThreshold        *float64 `json:"fair_threshold"`
+	GoodThreshold        *float64 `json:"good_threshold"`
+	OutstandingThreshold *float64 `json:"outstanding_threshold"`
+	ExceptionalThreshold *float64 `json:"exceptional_threshold"`
+}
+
+// GetKPITemplates - GET /api/kpi-templates
+func GetKPITemplates(c *gin.Context) {
+	var templates []models.KPITemplate
+	if err := db.Order("category asc, title asc").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data template KPI"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": templates})
+}
+
+// GetKPITemplate - GET /api/kpi-templates/:id
+func GetKPITemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var template models.KPITemplate
+	if err := db.First(&template, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template KPI tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// CreateKPITemplate - POST /api/kpi-templates
+func CreateKPITemplate(c *gin.Context) {
+	var input KPITemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var template models.KPITemplate
+	if !isiTemplateKPI(c, &template, input) {
+		return
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat template KPI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// UpdateKPITemplate - PUT /api/kpi-templates/:id
+// Perubahan template berlaku untuk semua penugasan yang tidak meng-override bobot/target, sehingga
+// ditolak (409) jika template ditugaskan pada periode terkunci, dan ditolak (400) jika bobot baru
+// membuat total bobot KPI salah satu pegawai melebihi 100. Skor KPI yang sudah memiliki nilai aktual
+// dihitung ulang dengan rubrik baru dalam transaksi yang sama.
+func UpdateKPITemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var template models.KPITemplate
+	if err := db.First(&template, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template KPI tidak ditemukan"})
+		return
+	}
+
+	var input KPITemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var periodeTerkunci []uint
+	if err := db.Model(&models.EvaluationPeriod{}).Where("status = ?", models.PeriodStatusLocked).Pluck("id", &periodeTerkunci).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa periode terkunci"})
+		return
+	}
+	var jumlahTerkunci int64
+	if err := db.Model(&models.KPI{}).Where("template_id = ? AND period_id IN ?", template.ID, periodeTerkunci).Count(&jumlahTerkunci).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa penugasan template"})
+		return
+	}
+	if jumlahTerkunci > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Template ditugaskan pada periode yang sudah dikunci, buat template baru"})
+		return
+	}
+
+	bobotLama := template.Weight
+	if !isiTemplateKPI(c, &template, input) {
+		return
+	}
+
+	// Bobot baru berlaku untuk penugasan tanpa weight_override
+	if template.Weight != bobotLama {
+		var kpis []models.KPI
+		if err := db.Where("template_id = ? AND weight_override IS NULL", template.ID).Find(&kpis).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil penugasan template"})
+			return
+		}
+		for _, k := range kpis {
+			if err := validasiBobotKPI(k.EmployeeID, k.PeriodID, k.ID, template.Weight); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Pegawai %d: %s", k.EmployeeID, err.Error())})
+				return
+			}
+		}
+	}
+
+	// Rubrik / target baru berlaku untuk KPI yang sudah memiliki nilai aktual (periode terkunci sudah ditolak di atas)
+	var dinilai []models.KPI
+	if err := db.Where("template_id = ? AND actual IS NOT NULL", template.ID).Find(&dinilai).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil penugasan template"})
+		return
+	}
+	skorBaru := make(map[uint]float64, len(dinilai))
+	for _, k := range dinilai {
+		k.Template = template
+		skor, err := HitungSkorRubrik(template, *k.Actual, targetNumerikKPI(k))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Pegawai %d: %s", k.EmployeeID, err.Error())})
+			return
+		}
+		skorBaru[k.ID] = skor
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&template).Error; err != nil {
+			return err
+		}
+		for id, skor := range skorBaru {
+			if err := tx.Model(&models.KPI{}).Where("id = ?", id).Update("score", skor).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui template KPI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// DeleteKPITemplate - DELETE /api/kpi-templates/:id
+// Template yang masih ditugaskan ke pegawai tidak dapat dihapus.
+func DeleteKPITemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var template models.KPITemplate
+	if err := db.First(&template, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template KPI tidak ditemukan"})
+		return
+	}
+
+	var jumlah int64
+	if err := db.Model(&models.KPI{}).Where("template_id = ?", template.ID).Count(&jumlah).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa penugasan template"})
+		return
+	}
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Template masih ditugaskan ke pegawai"})
+		return
+	}
+
+	if err := db.Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus template KPI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// AssignKPITemplate - POST /api/kpi-templates/:id/assign
+// Menugaskan template ke semua pegawai dengan role dan/atau departemen tertentu pada suatu periode.
+// Pegawai yang sudah memiliki penugasan template yang sama pada periode tersebut, atau yang total
+// bobot KPI-nya akan melebihi 100, dilewati.
+func AssignKPITemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var template models.KPITemplate
+	if err := db.First(&template, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template KPI tidak ditemukan"})
+		return
+	}
+
+	var input struct {
+		PeriodID       uint     `json:"period_id"`
+		Role           string   `json:"role"`
+		Department     string   `json:"department"`
+		WeightOverride *float64 `json:"weight_override"`
+		TargetOverride *string  `json:"target_override"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Role == "" && input.Department == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Isi role dan/atau department"})
+		return
+	}
+	if input.WeightOverride != nil && (*input.WeightOverride < 0 || *input.WeightOverride > 100) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight_override harus di antara 0 dan 100"})
+		return
+	}
+	if !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+
+	query := db.Model(&models.Employee{})
+	if input.Role != "" {
+		query = query.Where("role = ?", input.Role)
+	}
+	if input.Department != "" {
+		query = query.Where("department = ?", input.Department)
+	}
+	var empIDs []uint
+	if err := query.Pluck("id", &empIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pegawai"})
+		return
+	}
+
+	var sudahAda []uint
+	if err := db.Model(&models.KPI{}).
+		Where("template_id = ? AND period_id = ? AND employee_id IN ?", template.ID, input.PeriodID, empIDs).
+		Pluck("employee_id", &sudahAda).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa penugasan KPI"})
+		return
+	}
+	dilewati := map[uint]bool{}
+	for _, id := range sudahAda {
+		dilewati[id] = true
+	}
+
+	bobot := template.Weight
+	if input.WeightOverride != nil {
+		bobot = *input.WeightOverride
+	}
+
+	kpis := []models.KPI{}
+	melebihiBobot := []uint{}
+	for _, empID := range empIDs {
+		if dilewati[empID] {
+			continue
+		}
+		if err := validasiBobotKPI(empID, input.PeriodID, 0, bobot); err != nil {
+			melebihiBobot = append(melebihiBobot, empID)
+			continue
+		}
+		kpis = append(kpis, models.KPI{
+			TemplateID:     template.ID,
+			EmployeeID:     empID,
+			PeriodID:       input.PeriodID,
+			WeightOverride: input.WeightOverride,
+			TargetOverride: input.TargetOverride,
+		})
+	}
+	if len(kpis) > 0 {
+		if err := db.Omit("Template").Create(&kpis).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menugaskan template KPI"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":           kpis,
+		"dibuat":         len(kpis),
+		"dilewati":       len(dilewati),
+		"melebihi_bobot": melebihiBobot,
+	})
+}
+
+// isiTemplateKPI memvalidasi input lalu mengisi field template KPI.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiTemplateKPI(c *gin.Context, template *models.KPITemplate, input KPITemplateInput) bool {
+	if input.Weight < 0 || input.Weight > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight harus di antara 0 dan 100"})
+		return false
+	}
+
+	template.Title = input.Title
+	template.Category = input.Category
+	template.Weight = input.Weight
+	template.Target = input.Target
+	template.Poor = input.Poor
+	template.Fair = input.Fair
+	template.Good = input.Good
+	template.Outstanding = input.Outstanding
+	template.Exceptional = input.Exceptional
+	template.RubricMode = input.RubricMode
+	template.TargetValue = input.TargetValue
+	template.FairThreshold = input.FairThreshold
+	template.GoodThreshold = input.GoodThreshold
+	template.OutstandingThreshold = input.OutstandingThreshold
+	template.ExceptionalThreshold = input.ExceptionalThreshold
+
+	if err := validasiRubrik(*template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"time"
+
+	"bonus/models"
+)
+
+// HitungFaktorProrata => proporsi hari kerja yang memenuhi syarat dalam periode (0..1).
+// Hari dihitung sejak tanggal masuk (hire_date) s.d. tanggal berhenti (termination_date),
+// dikurangi hari cuti tidak dibayar. Tanpa periode, faktor = 1.
+func HitungFaktorProrata(emp models.Employee, periode *models.EvaluationPeriod) (float64, error) {
+	if periode == nil {
+		return 1, nil
+	}
+
+	mulai := tanggalSaja(periode.StartDate)
+	selesai := tanggalSaja(periode.EndDate)
+	totalHari := hariInklusif(mulai, selesai)
+	if totalHari <= 0 {
+		return 1, nil
+	}
+
+	// Rentang masa kerja di dalam periode
+	awal, akhir := mulai, selesai
+	if emp.HireDate != nil && tanggalSaja(*emp.HireDate).After(awal) {
+		awal = tanggalSaja(*emp.HireDate)
+	}
+	if emp.TerminationDate != nil && tanggalSaja(*emp.TerminationDate).Before(akhir) {
+		akhir = tanggalSaja(*emp.TerminationDate)
+	}
+	if akhir.Before(awal) {
+		return 0, nil
+	}
+
+	var leaves []models.EmployeeLeave
+	err := db.Where("employee_id = ? AND unpaid = ? AND start_date <= ? AND end_date >= ?", emp.ID, true, akhir, awal).
+		Find(&leaves).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// Tandai hari cuti tidak dibayar (interval cuti boleh saling tumpang tindih)
+	hariCuti := map[time.Time]bool{}
+	for _, l := range leaves {
+		for d := tanggalSaja(l.StartDate); !d.After(tanggalSaja(l.EndDate)); d = d.AddDate(0, 0, 1) {
+			if !d.Before(awal) && !d.After(akhir) {
+				hariCuti[d] = true
+			}
+		}
+	}
+
+	hariKerja := hariInklusif(awal, akhir) - len(hariCuti)
+	if hariKerja < 0 {
+		hariKerja = 0
+	}
+	return float64(hariKerja) / float64(totalHari), nil
+}
+
+// tanggalSaja => tanggal tanpa jam & zona waktu (agar perhitungan hari konsisten)
+func tanggalSaja(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// hariInklusif => jumlah hari dari a s.d. b (termasuk keduanya)
+func hariInklusif(a, b time.Time) int {
+	return int(b.Sub(a).Hours()/24) + 1
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KontribusiReward => rincian penambahan poin dari satu reward
+type KontribusiReward struct {
+	RewardID uint      `json:"reward_id"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Points   float64   `json:"points"`
+	Poin     float64   `json:"poin"` // poin yang benar-benar ditambahkan (setelah batas kategori)
+}
+
+// GetRewards - GET /api/rewards?period_id=
+// Mengambil daftar reward (opsional: filter periode).
+func GetRewards(c *gin.Context) {
+	periode, ok := periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	var rewards []models.Reward
+	if err := filterPeriode(db.Preload("Employee"), periode).Find(&rewards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data reward"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rewards})
+}
+
+// CreateReward - POST /api/rewards
+// Membuat reward baru (status pending, menunggu persetujuan).
+// Jika points kosong, diisi dari poin default kategori.
+func CreateReward(c *gin.Context) {
+	var input struct {
+		EmployeeID  uint     `json:"employee_id" binding:"required"`
+		Category    string   `json:"category" binding:"required"`
+		Date        string   `json:"date" binding:"required"` // Format "YYYY-MM-DD"
+		Description string   `json:"description"`
+		Points      *float64 `json:"points"`
+		PeriodID    uint     `json:"period_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format date tidak valid (YYYY-MM-DD)"})
+		return
+	}
+	category, ok := kategoriReward(input.Category)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori reward " + input.Category + " tidak ditemukan"})
+		return
+	}
+
+	reward := models.Reward{
+		EmployeeID:  input.EmployeeID,
+		Category:    category.Code,
+		Date:        date,
+		Description: input.Description,
+		Points:      category.DefaultPoints,
+		PeriodID:    input.PeriodID,
+		Status:      models.RewardStatusPending,
+	}
+	if input.Points != nil {
+		reward.Points = *input.Points
+	}
+	if reward.Points < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "points tidak boleh negatif"})
+		return
+	}
+	// Reward dihitung pada periode yang mencakup tanggalnya
+	if !validasiRentangTidakTerkunci(c, reward.Date, reward.Date) {
+		return
+	}
+
+	if err := db.Create(&reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reward})
+}
+
+// UpdateReward - PUT /api/rewards/:id
+// Memperbarui data reward. Reward yang diubah kembali berstatus pending.
+func UpdateReward(c *gin.Context) {
+	var reward models.Reward
+	if err := db.First(&reward, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward tidak ditemukan"})
+		return
+	}
+
+	var input struct {
+		EmployeeID  uint     `json:"employee_id"`
+		Category    string   `json:"category"`
+		Date        string   `json:"date"`
+		Description string   `json:"description"`
+		Points      *float64 `json:"points"`
+		PeriodID    uint     `json:"period_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Periode lama maupun periode baru tidak boleh terkunci
+	if !validasiPeriodeInput(c, reward.PeriodID) || !validasiPeriodeInput(c, input.PeriodID) {
+		return
+	}
+	if !validasiRentangTidakTerkunci(c, reward.Date, reward.Date) {
+		return
+	}
+
+	// Jika field kosong, biarkan data lama
+	if input.EmployeeID != 0 {
+		reward.EmployeeID = input.EmployeeID
+	}
+	if input.Category != "" {
+		if _, ok := kategoriReward(input.Category); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori reward " + input.Category + " tidak ditemukan"})
+			return
+		}
+		reward.Category = input.Category
+	}
+	if input.Date != "" {
+		date, err := time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format date tidak valid (YYYY-MM-DD)"})
+			return
+		}
+		reward.Date = date
+	}
+	if input.Description != "" {
+		reward.Description = input.Description
+	}
+	if input.Points != nil {
+		if *input.Points < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "points tidak boleh negatif"})
+			return
+		}
+		reward.Points = *input.Points
+	}
+	if input.PeriodID != 0 {
+		reward.PeriodID = input.PeriodID
+	}
+	if !validasiRentangTidakTerkunci(c, reward.Date, reward.Date) {
+		return
+	}
+	reward.Status = models.RewardStatusPending
+	reward.ApprovedBy = 0
+	reward.ApprovedAt = nil
+
+	if err := db.Omit("Employee").Save(&reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui data reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reward})
+}
+
+// DeleteReward - DELETE /api/rewards/:id
+func DeleteReward(c *gin.Context) {
+	var reward models.Reward
+	if err := db.First(&reward, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward tidak ditemukan"})
+		return
+	}
+	if !validasiPeriodeInput(c, reward.PeriodID) || !validasiRentangTidakTerkunci(c, reward.Date, reward.Date) {
+		return
+	}
+
+	if err := db.Delete(&reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "Reward berhasil dihapus"})
+}
+
+// DecideReward - POST /api/rewards/:id/decision (khusus HR / admin)
+// pending: approve => approved, reject => rejected
+func DecideReward(c *gin.Context) {
+	var reward models.Reward
+	if err := db.First(&reward, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward tidak ditemukan"})
+		return
+	}
+	if reward.Status != models.RewardStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reward berstatus " + reward.Status + " tidak dapat diputuskan lagi"})
+		return
+	}
+	if !validasiPeriodeInput(c, reward.PeriodID) || !validasiRentangTidakTerkunci(c, reward.Date, reward.Date) {
+		return
+	}
+
+	var input struct {
+		Decision string `json:"decision" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch input.Decision {
+	case "approve":
+		reward.Status = models.RewardStatusApproved
+	case "reject":
+		reward.Status = models.RewardStatusRejected
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision harus approve atau reject"})
+		return
+	}
+	now := time.Now()
+	reward.ApprovedBy = idPenggunaLogin(c)
+	reward.ApprovedAt = &now
+
+	if err := db.Save(&reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan keputusan reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reward})
+}
+
+// ambilRewardPegawai => reward approved milik pegawai pada periode (period_id periode tsb, atau
+// tanpa period_id namun tanggalnya di dalam rentang periode). Tanpa periode => semua reward approved.
+func ambilRewardPegawai(empID uint, periode *models.EvaluationPeriod) ([]models.Reward, error) {
+	query := db.Where("employee_id = ? AND status = ?", empID, models.RewardStatusApproved)
+	if periode != nil {
+		query = query.Where("period_id = ? OR (period_id = 0 AND date BETWEEN ? AND ?)", periode.ID, periode.StartDate, periode.EndDate)
+	}
+
+	var rewards []models.Reward
+	if err := query.Order("date asc, id asc").Find(&rewards).Error; err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+// HitungKontribusiReward => rincian & total penambah poin. Poin per kategori dibatasi oleh
+// max_points kategori; reward diperhitungkan sesuai urutan (ambilRewardPegawai => tanggal terlama dulu).
+func HitungKontribusiReward(rewards []models.Reward, batas map[string]float64) ([]KontribusiReward, float64) {
+	kontribusi := []KontribusiReward{}
+	terpakai := map[string]float64{}
+	var total float64
+	for _, r := range rewards {
+		poin := r.Points
+		if maks, ok := batas[r.Category]; ok {
+			poin = min(poin, maks-terpakai[r.Category])
+			if poin < 0 {
+				poin = 0
+			}
+		}
+		terpakai[r.Category] += poin
+		total += poin
+		kontribusi = append(kontribusi, KontribusiReward{
+			RewardID: r.ID,
+			Category: r.Category,
+			Date:     r.Date,
+			Points:   r.Points,
+			Poin:     RoundFloat(poin, 3),
+		})
+	}
+	return kontribusi, total
+}
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RewardCategoryInput adalah payload untuk pembuatan / update kategori reward
+type RewardCategoryInput struct {
+	Code          string  `json:"code" binding:"required"`
+	Name          string  `json:"name" binding:"required"`
+	DefaultPoints float64 `json:"default_points"`
+	MaxPoints     float64 `json:"max_points"`
+}
+
+// GetRewardCategories - GET /api/reward-categories
+func GetRewardCategories(c *gin.Context) {
+	var categories []models.RewardCategory
+	if err := db.Order("code asc").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori reward"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// CreateRewardCategory - POST /api/reward-categories
+func CreateRewardCategory(c *gin.Context) {
+	var input RewardCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var category models.RewardCategory
+	if !isiKategoriReward(c, &category, input) {
+		return
+	}
+
+	if err := db.Create(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code kategori reward " + category.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kategori reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// UpdateRewardCategory - PUT /api/reward-categories/:id
+// Code hanya dapat diganti selama kategori belum dipakai reward (409).
+func UpdateRewardCategory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var category models.RewardCategory
+	if err := db.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kategori reward tidak ditemukan"})
+		return
+	}
+
+	var input RewardCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Code direferensikan reward (dan batas poinnya), jadi hanya boleh diganti selama belum dipakai
+	if input.Code != category.Code && !validasiKategoriRewardTidakDipakai(c, category.Code) {
+		return
+	}
+	if !isiKategoriReward(c, &category, input) {
+		return
+	}
+
+	if err := db.Save(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Code kategori reward " + category.Code + " sudah dipakai"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kategori reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// DeleteRewardCategory - DELETE /api/reward-categories/:id
+func DeleteRewardCategory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var category models.RewardCategory
+	if err := db.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kategori reward tidak ditemukan"})
+		return
+	}
+
+	// Kategori dihapus permanen (agar code bisa dipakai ulang), jadi tolak selama masih dipakai reward
+	if !validasiKategoriRewardTidakDipakai(c, category.Code) {
+		return
+	}
+
+	if err := db.Unscoped().Delete(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kategori reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// validasiKategoriRewardTidakDipakai memastikan code kategori tidak direferensikan reward.
+// Jika masih dipakai, response 409 langsung dikirim dan mengembalikan false.
+func validasiKategoriRewardTidakDipakai(c *gin.Context, code string) bool {
+	var jumlah int64
+	if err := db.Model(&models.Reward{}).Where("category = ?", code).Count(&jumlah).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pemakaian kategori reward"})
+		return false
+	}
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kategori reward " + code + " masih dipakai oleh reward"})
+		return false
+	}
+	return true
+}
+
+// isiKategoriReward memvalidasi input lalu mengisi field kategori reward.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiKategoriReward(c *gin.Context, category *models.RewardCategory, input RewardCategoryInput) bool {
+	if input.DefaultPoints < 0 || input.MaxPoints < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default_points dan max_points tidak boleh negatif"})
+		return false
+	}
+	if input.MaxPoints > 0 && input.DefaultPoints > input.MaxPoints {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default_points tidak boleh melebihi max_points"})
+		return false
+	}
+
+	category.Code = input.Code
+	category.Name = input.Name
+	category.DefaultPoints = input.DefaultPoints
+	category.MaxPoints = input.MaxPoints
+	return true
+}
+
+// kategoriReward => kategori pada katalog berdasarkan code (ok = false jika tidak ada)
+func kategoriReward(code string) (models.RewardCategory, bool) {
+	var category models.RewardCategory
+	if err := db.Where("code = ?", code).First(&category).Error; err != nil {
+		return category, false
+	}
+	return category, true
+}
+
+// batasPoinReward => code kategori => batas total poin per pegawai per periode (hanya yang dibatasi)
+func batasPoinReward() (map[string]float64, error) {
+	var categories []models.RewardCategory
+	if err := db.Where("max_points > 0").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	batas := map[string]float64{}
+	for _, k := range categories {
+		batas[k.Code] = k.MaxPoints
+	}
+	return batas, nil
+}
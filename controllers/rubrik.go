@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Nama level rubrik sesuai urutan ambang (Fair s.d. Exceptional). Di bawah ambang Fair => Poor (1).
+var levelRubrik = []string{"fair", "good", "outstanding", "exceptional"}
+
+// ambangRubrik => ambang Fair, Good, Outstanding, Exceptional dari template (nil jika ada yang kosong)
+func ambangRubrik(t models.KPITemplate) []float64 {
+	ambang := []*float64{t.FairThreshold, t.GoodThreshold, t.OutstandingThreshold, t.ExceptionalThreshold}
+	hasil := make([]float64, len(ambang))
+	for i, a := range ambang {
+		if a == nil {
+			return nil
+		}
+		hasil[i] = *a
+	}
+	return hasil
+}
+
+// validasiRubrik memastikan ambang rubrik lengkap dan berurutan sesuai arah mode rubrik.
+func validasiRubrik(t models.KPITemplate) error {
+	switch t.RubricMode {
+	case "":
+		return nil
+	case models.RubricHigherIsBetter, models.RubricLowerIsBetter, models.RubricPercentOfTarget:
+	default:
+		return errors.New("rubric_mode harus higher_is_better, lower_is_better atau percent_of_target")
+	}
+
+	ambang := ambangRubrik(t)
+	if ambang == nil {
+		return errors.New("ambang fair, good, outstanding dan exceptional wajib diisi untuk rubrik")
+	}
+	turun := t.RubricMode == models.RubricLowerIsBetter
+	for i := 1; i < len(ambang); i++ {
+		if (!turun && ambang[i] <= ambang[i-1]) || (turun && ambang[i] >= ambang[i-1]) {
+			arah := "naik"
+			if turun {
+				arah = "turun"
+			}
+			return fmt.Errorf("ambang %s harus %s dibanding ambang %s", levelRubrik[i], arah, levelRubrik[i-1])
+		}
+	}
+	return nil
+}
+
+// HitungSkorRubrik mengubah nilai aktual menjadi skor 1–5 berdasarkan rubrik template.
+//   - higher_is_better: skor = level tertinggi yang ambangnya <= aktual
+//   - lower_is_better: skor = level tertinggi yang ambangnya >= aktual
+//   - percent_of_target: aktual diubah menjadi persen dari target, lalu seperti higher_is_better
+func HitungSkorRubrik(t models.KPITemplate, aktual float64, target *float64) (float64, error) {
+	ambang := ambangRubrik(t)
+	if t.RubricMode == "" || ambang == nil {
+		return 0, errors.New("template KPI belum memiliki rubrik")
+	}
+
+	nilai := aktual
+	if t.RubricMode == models.RubricPercentOfTarget {
+		if target == nil || *target == 0 {
+			return 0, errors.New("target numerik wajib diisi untuk rubrik percent_of_target")
+		}
+		nilai = aktual / *target * 100
+	}
+
+	skor := 1.0
+	for i, a := range ambang {
+		tercapai := nilai >= a
+		if t.RubricMode == models.RubricLowerIsBetter {
+			tercapai = nilai <= a
+		}
+		if tercapai {
+			skor = float64(i + 2)
+		}
+	}
+	return skor, nil
+}
+
+// SubmitKPIActual - POST /api/kpis/:id/actual
+// Menyimpan nilai aktual KPI lalu menghitung skor 1–5 dari rubrik template.
+// KPI yang sudah divalidasi ditolak (409).
+func SubmitKPIActual(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var kpi models.KPI
+	if err := db.Preload("Template").First(&kpi, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "KPI tidak ditemukan"})
+		return
+	}
+	if !validasiPeriodeInput(c, kpi.PeriodID) {
+		return
+	}
+	if kpi.Validated {
+		c.JSON(http.StatusConflict, gin.H{"error": "KPI sudah divalidasi, nilai aktual tidak dapat diubah"})
+		return
+	}
+
+	var input struct {
+		Actual *float64 `json:"actual" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "actual wajib diisi"})
+		return
+	}
+
+	skor, err := HitungSkorRubrik(kpi.Template, *input.Actual, targetNumerikKPI(kpi))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	kpi.Actual = input.Actual
+	kpi.ActualSubmittedAt = &now
+	kpi.Score = skor
+	if err := db.Omit("Template").Save(&kpi).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan nilai aktual KPI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": kpi})
+}
+
+// targetNumerikKPI => target numerik efektif penugasan KPI (override atau target template)
+func targetNumerikKPI(kpi models.KPI) *float64 {
+	if kpi.TargetValueOverride != nil {
+		return kpi.TargetValueOverride
+	}
+	return kpi.Template.TargetValue
+}
@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScaleBandInput adalah payload satu band pada tabel skala
+type ScaleBandInput struct {
+	Label       string   `json:"label" binding:"required"`
+	LowerBound  float64  `json:"lower_bound"`
+	UpperBound  *float64 `json:"upper_bound"` // kosongkan untuk band teratas
+	Multiplier  float64  `json:"multiplier"`
+	FixedAmount *float64 `json:"fixed_amount"`
+}
+
+// ScaleTableInput adalah payload untuk pembuatan / update tabel skala
+type ScaleTableInput struct {
+	Name          string           `json:"name" binding:"required"`
+	EffectiveFrom string           `json:"effective_from" binding:"required"` // Format "YYYY-MM-DD"
+	Bands         []ScaleBandInput `json:"bands" binding:"required"`
+}
+
+// GetScaleTables - GET /api/scale-tables
+func GetScaleTables(c *gin.Context) {
+	var tables []models.ScaleTable
+	if err := db.Preload("Bands", urutBand).Order("effective_from desc, version desc").Find(&tables).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data tabel skala"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tables})
+}
+
+// GetScaleTable - GET /api/scale-tables/:id
+func GetScaleTable(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var table models.ScaleTable
+	if err := db.Preload("Bands", urutBand).First(&table, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tabel skala tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": table})
+}
+
+// CreateScaleTable - POST /api/scale-tables
+// Membuat versi baru tabel skala (versi = versi terakhir + 1). Ditolak (409) jika versi baru
+// akan berlaku untuk periode yang sudah dikunci (effective_from <= tanggal mulai periode tersebut).
+func CreateScaleTable(c *gin.Context) {
+	var input ScaleTableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var table models.ScaleTable
+	if !isiTabelSkala(c, &table, input) {
+		return
+	}
+
+	// Versi baru tidak boleh berlaku untuk periode yang sudah dikunci
+	var versiSekarang int
+	if err := db.Model(&models.ScaleTable{}).Select("COALESCE(MAX(version), 0)").Scan(&versiSekarang).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tabel skala"})
+		return
+	}
+	table.Version = versiSekarang + 1
+	if !validasiSkalaTidakTerkunci(c, &table) {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var versiTerakhir int
+		if err := tx.Model(&models.ScaleTable{}).Select("COALESCE(MAX(version), 0)").Scan(&versiTerakhir).Error; err != nil {
+			return err
+		}
+		table.Version = versiTerakhir + 1
+		return tx.Create(&table).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tabel skala"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": table})
+}
+
+// UpdateScaleTable - PUT /api/scale-tables/:id
+// Mengganti nama, tanggal berlaku dan seluruh band pada tabel skala.
+// Ditolak (409) jika tabel dipakai (sebelum maupun sesudah perubahan) oleh periode yang sudah dikunci;
+// buat versi baru dengan effective_from setelah periode tersebut.
+func UpdateScaleTable(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var table models.ScaleTable
+	if err := db.First(&table, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tabel skala tidak ditemukan"})
+		return
+	}
+
+	var input ScaleTableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isiTabelSkala(c, &table, input) {
+		return
+	}
+	if !validasiSkalaTidakTerkunci(c, &table) {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scale_table_id = ?", table.ID).Delete(&models.ScaleBand{}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&table).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui tabel skala"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": table})
+}
+
+// DeleteScaleTable - DELETE /api/scale-tables/:id
+func DeleteScaleTable(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var table models.ScaleTable
+	if err := db.First(&table, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tabel skala tidak ditemukan"})
+		return
+	}
+	if !validasiSkalaTidakTerkunci(c, &table) {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scale_table_id = ?", table.ID).Delete(&models.ScaleBand{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&table).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tabel skala"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+// validasiSkalaTidakTerkunci memastikan tidak ada periode terkunci yang memakai tabel skala, baik
+// tabel yang tersimpan saat ini maupun dengan effective_from pada table (tabel baru / diubah).
+// Jika ada, response 409 langsung dikirim dan mengembalikan false.
+func validasiSkalaTidakTerkunci(c *gin.Context, table *models.ScaleTable) bool {
+	var periods []models.EvaluationPeriod
+	if err := db.Where("status = ?", models.PeriodStatusLocked).Find(&periods).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa periode terkunci"})
+		return false
+	}
+
+	for i := range periods {
+		berlaku, _, err := SkalaUntukPeriode(&periods[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tabel skala"})
+			return false
+		}
+
+		// Dipakai saat ini, atau akan menggantikan tabel yang berlaku setelah effective_from diubah
+		dipakai := berlaku != nil && berlaku.ID == table.ID
+		if !dipakai && !table.EffectiveFrom.After(periods[i].StartDate) {
+			dipakai = berlaku == nil ||
+				table.EffectiveFrom.After(berlaku.EffectiveFrom) ||
+				(table.EffectiveFrom.Equal(berlaku.EffectiveFrom) && table.Version > berlaku.Version)
+		}
+		if dipakai {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tabel skala dipakai periode " + periods[i].Name + " yang sudah dikunci, buat versi baru"})
+			return false
+		}
+	}
+	return true
+}
+
+// isiTabelSkala memvalidasi input lalu mengisi field tabel skala beserta band-nya.
+// Jika tidak valid, response error langsung dikirim dan mengembalikan false.
+func isiTabelSkala(c *gin.Context, table *models.ScaleTable, input ScaleTableInput) bool {
+	effective, err := time.Parse("2006-01-02", input.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format effective_from tidak valid (YYYY-MM-DD)"})
+		return false
+	}
+
+	bands := make([]models.ScaleBand, 0, len(input.Bands))
+	for _, b := range input.Bands {
+		bands = append(bands, models.ScaleBand{
+			Label:       b.Label,
+			LowerBound:  b.LowerBound,
+			UpperBound:  b.UpperBound,
+			Multiplier:  b.Multiplier,
+			FixedAmount: b.FixedAmount,
+		})
+	}
+	sort.SliceStable(bands, func(i, j int) bool { return bands[i].LowerBound < bands[j].LowerBound })
+
+	if err := validasiBand(bands); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	table.Name = input.Name
+	table.EffectiveFrom = effective
+	table.Bands = bands
+	return true
+}
+
+// validasiBand memastikan band terurut tidak memiliki celah (gap) maupun tumpang tindih (overlap).
+// Hanya band teratas yang boleh tanpa batas atas.
+func validasiBand(bands []models.ScaleBand) error {
+	if len(bands) == 0 {
+		return fmt.Errorf("tabel skala minimal memiliki satu band")
+	}
+
+	label := map[string]bool{}
+	for i, b := range bands {
+		if label[b.Label] {
+			return fmt.Errorf("label band %q duplikat", b.Label)
+		}
+		label[b.Label] = true
+
+		if b.Multiplier < 0 || (b.FixedAmount != nil && *b.FixedAmount < 0) {
+			return fmt.Errorf("band %q: multiplier dan fixed_amount tidak boleh negatif", b.Label)
+		}
+
+		terakhir := i == len(bands)-1
+		if b.UpperBound == nil {
+			if !terakhir {
+				return fmt.Errorf("band %q: hanya band teratas yang boleh tanpa upper_bound", b.Label)
+			}
+			continue
+		}
+		if *b.UpperBound <= b.LowerBound {
+			return fmt.Errorf("band %q: upper_bound harus lebih besar dari lower_bound", b.Label)
+		}
+		if terakhir {
+			continue
+		}
+
+		berikut := bands[i+1]
+		switch {
+		case *b.UpperBound < berikut.LowerBound:
+			return fmt.Errorf("celah antara band %q (< %.2f) dan %q (>= %.2f)", b.Label, *b.UpperBound, berikut.Label, berikut.LowerBound)
+		case *b.UpperBound > berikut.LowerBound:
+			return fmt.Errorf("band %q (< %.2f) tumpang tindih dengan %q (>= %.2f)", b.Label, *b.UpperBound, berikut.Label, berikut.LowerBound)
+		}
+	}
+	return nil
+}
+
+// SkalaUntukPeriode mengambil tabel skala yang berlaku untuk periode
+// (EffectiveFrom terakhir <= tanggal mulai periode; tanpa periode => per hari ini).
+// Jika belum ada tabel tersimpan, dikembalikan SkalaDefault dengan tabel = nil.
+func SkalaUntukPeriode(periode *models.EvaluationPeriod) (*models.ScaleTable, []models.ScaleBand, error) {
+	tanggal := time.Now()
+	if periode != nil {
+		tanggal = periode.StartDate
+	}
+
+	var table models.ScaleTable
+	err := db.Preload("Bands", urutBand).
+		Where("effective_from <= ?", tanggal).
+		Order("effective_from desc, version desc").
+		First(&table).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, SkalaDefault, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(table.Bands) == 0 {
+		return &table, SkalaDefault, nil
+	}
+	return &table, table.Bands, nil
+}
+
+// urutBand => preload band terurut dari batas bawah terkecil
+func urutBand(query *gorm.DB) *gorm.DB {
+	return query.Order("lower_bound asc")
+}
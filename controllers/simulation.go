@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"bonus/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SimulasiRequest adalah payload simulasi bonus (what-if). Tidak ada data yang disimpan.
+type SimulasiRequest struct {
+	PeriodID uint `json:"period_id"`
+
+	// Pool dasar (opsional). Jika kosong, bonus dihitung dari gaji x multiplier.
+	Pool *PoolInput `json:"pool"`
+
+	Overrides SimulasiOverride `json:"overrides"`
+}
+
+// PoolInput => anggaran bonus beserta sub-pool departemen (opsional)
+type PoolInput struct {
+	TotalBudget     int64            `json:"total_budget"`
+	DepartmentPools map[string]int64 `json:"department_pools"`
+}
+
+// SimulasiOverride berisi perubahan yang ingin dicoba terhadap konfigurasi saat ini.
+type SimulasiOverride struct {
+	ScaleBands        []ScaleBandInput   `json:"scale_bands"`         // ganti seluruh tabel skala
+	Multipliers       map[string]float64 `json:"multipliers"`         // ganti multiplier per label, mis. {"Outstanding": 3.5}
+	ScaleMode         string             `json:"scale_mode"`          // step / linear
+	CriteriaWeights   map[string]float64 `json:"criteria_weights"`    // total harus 1
+	PoolTotal         *int64             `json:"pool_total"`          // ganti total anggaran pool
+	PoolChangePercent *float64           `json:"pool_change_percent"` // mis. -10 => pool 10% lebih kecil
+	ScoreAdjustments  []PenyesuaianInput `json:"score_adjustments"`
+}
+
+// PenyesuaianInput => tambahan/pengurangan KPI setelah kalibrasi untuk satu pegawai
+type PenyesuaianInput struct {
+	EmployeeID uint    `json:"employee_id" binding:"required"`
+	Delta      float64 `json:"delta"`
+}
+
+// HasilSimulasi => perbandingan hasil baseline & skenario untuk satu pegawai
+type HasilSimulasi struct {
+	EmployeeID    uint    `json:"employee_id"`
+	Name          string  `json:"name"`
+	Department    string  `json:"department"`
+	KPIBaseline   float64 `json:"kpi_baseline"`
+	KPI           float64 `json:"kpi"`
+	SkalaBaseline string  `json:"skala_baseline"`
+	Skala         string  `json:"skala"`
+	BonusBaseline float64 `json:"bonus_baseline"`
+	Bonus         float64 `json:"bonus"`
+	Delta         float64 `json:"delta"`
+}
+
+// SimulateBonus - POST /api/bonus/simulate
+// Menjalankan perhitungan yang sama dengan GetKalibrasi dengan override (skala, bobot kriteria,
+// pool, penyesuaian skor) lalu membandingkannya dengan kondisi saat ini (baseline).
+func SimulateBonus(c *gin.Context) {
+	var req SimulasiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periode, ok := ambilPeriode(c, req.PeriodID)
+	if !ok {
+		return
+	}
+	// Persentase perubahan pool butuh pool dasar (pool atau overrides.pool_total)
+	if req.Overrides.PoolChangePercent != nil && req.Pool == nil && req.Overrides.PoolTotal == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pool_change_percent membutuhkan pool atau pool_total"})
+		return
+	}
+
+	opsi, ok := opsiSimulasi(c, periode, req.Overrides)
+	if !ok {
+		return
+	}
+
+	baseline, err := HitungKalibrasi(OpsiKalibrasi{Periode: periode})
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+	skenario, err := HitungKalibrasi(opsi)
+	if err != nil {
+		kirimErrorKalibrasi(c, err)
+		return
+	}
+
+	// Pool skenario = pool dasar yang diganti / diubah persentasenya
+	poolSkenario := req.Pool
+	if req.Overrides.PoolTotal != nil || req.Overrides.PoolChangePercent != nil {
+		p := PoolInput{}
+		if req.Pool != nil {
+			p = *req.Pool
+		}
+		if req.Overrides.PoolTotal != nil {
+			p.TotalBudget = *req.Overrides.PoolTotal
+		}
+		if req.Overrides.PoolChangePercent != nil {
+			p.TotalBudget = int64(float64(p.TotalBudget) * (1 + *req.Overrides.PoolChangePercent/100))
+		}
+		poolSkenario = &p
+	}
+
+	bonusBaseline, err := bonusPerPegawai(baseline.Rows, req.Pool)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	bonusSkenario, err := bonusPerPegawai(skenario.Rows, poolSkenario)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	barisBaseline := map[uint]KalibrasiResponse{}
+	for _, r := range baseline.Rows {
+		barisBaseline[r.EmployeeID] = r
+	}
+
+	data := []HasilSimulasi{}
+	var totalBaseline, totalSkenario float64
+	for _, r := range skenario.Rows {
+		b := barisBaseline[r.EmployeeID]
+		item := HasilSimulasi{
+			EmployeeID:    r.EmployeeID,
+			Name:          r.Name,
+			Department:    r.Department,
+			KPIBaseline:   b.KPISetelahKalibrasi,
+			KPI:           r.KPISetelahKalibrasi,
+			SkalaBaseline: b.Skala,
+			Skala:         r.Skala,
+			BonusBaseline: bonusBaseline[r.EmployeeID],
+			Bonus:         bonusSkenario[r.EmployeeID],
+		}
+		item.Delta = item.Bonus - item.BonusBaseline
+		totalBaseline += item.BonusBaseline
+		totalSkenario += item.Bonus
+		data = append(data, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"total": gin.H{
+			"bonus_baseline": totalBaseline,
+			"bonus":          totalSkenario,
+			"delta":          totalSkenario - totalBaseline,
+		},
+		"periode": periode,
+	})
+}
+
+// opsiSimulasi memvalidasi override lalu menyusunnya menjadi OpsiKalibrasi.
+// Jika tidak valid, response error langsung dikirim dan ok = false.
+func opsiSimulasi(c *gin.Context, periode *models.EvaluationPeriod, o SimulasiOverride) (OpsiKalibrasi, bool) {
+	opsi := OpsiKalibrasi{Periode: periode, ModeSkala: o.ScaleMode}
+
+	if o.ScaleMode != "" && o.ScaleMode != models.ScaleModeStep && o.ScaleMode != models.ScaleModeLinear {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scale_mode harus step atau linear"})
+		return opsi, false
+	}
+
+	// Tabel skala: override penuh, atau tabel yang berlaku dengan multiplier yang diganti
+	if len(o.ScaleBands) > 0 || len(o.Multipliers) > 0 {
+		var bands []models.ScaleBand
+		if len(o.ScaleBands) > 0 {
+			for _, b := range o.ScaleBands {
+				bands = append(bands, models.ScaleBand{
+					Label:       b.Label,
+					LowerBound:  b.LowerBound,
+					UpperBound:  b.UpperBound,
+					Multiplier:  b.Multiplier,
+					FixedAmount: b.FixedAmount,
+				})
+			}
+			sort.SliceStable(bands, func(i, j int) bool { return bands[i].LowerBound < bands[j].LowerBound })
+		} else {
+			_, berlaku, err := SkalaUntukPeriode(periode)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tabel skala"})
+				return opsi, false
+			}
+			bands = append(bands, berlaku...)
+		}
+
+		for label, m := range o.Multipliers {
+			ditemukan := false
+			for i := range bands {
+				if bands[i].Label == label {
+					bands[i].Multiplier = m
+					ditemukan = true
+				}
+			}
+			if !ditemukan {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Label skala " + label + " tidak ada pada tabel skala"})
+				return opsi, false
+			}
+		}
+
+		if err := validasiBand(bands); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return opsi, false
+		}
+		opsi.Bands = bands
+	}
+
+	if len(o.CriteriaWeights) > 0 {
+		if err := validasiBobotRequest(o.CriteriaWeights); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return opsi, false
+		}
+		opsi.BobotKriteria = o.CriteriaWeights
+	}
+
+	if len(o.ScoreAdjustments) > 0 {
+		opsi.PenyesuaianSkor = map[uint]float64{}
+		for _, p := range o.ScoreAdjustments {
+			opsi.PenyesuaianSkor[p.EmployeeID] += p.Delta
+		}
+
+		// Penyesuaian untuk pegawai yang tidak ada akan diabaikan diam-diam, jadi tolak
+		ids := make([]uint, 0, len(opsi.PenyesuaianSkor))
+		for id := range opsi.PenyesuaianSkor {
+			ids = append(ids, id)
+		}
+		var ditemukan []uint
+		if err := db.Model(&models.Employee{}).Where("id IN ?", ids).Pluck("id", &ditemukan).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa data pegawai"})
+			return opsi, false
+		}
+		ada := make(map[uint]bool, len(ditemukan))
+		for _, id := range ditemukan {
+			ada[id] = true
+		}
+		for _, id := range ids {
+			if !ada[id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("score_adjustments: pegawai dengan ID %d tidak ditemukan", id)})
+				return opsi, false
+			}
+		}
+	}
+
+	return opsi, true
+}
+
+// bonusPerPegawai => bonus tiap pegawai: hasil alokasi pool jika pool diisi, selain itu bonus kalibrasi
+func bonusPerPegawai(rows []KalibrasiResponse, pool *PoolInput) (map[uint]float64, error) {
+	bonus := make(map[uint]float64, len(rows))
+	if pool == nil {
+		for _, r := range rows {
+			bonus[r.EmployeeID] = r.Bonus
+		}
+		return bonus, nil
+	}
+
+	alokasi, _, err := AlokasikanPool(rows, pool.TotalBudget, pool.DepartmentPools)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range alokasi {
+		bonus[a.EmployeeID] = float64(a.Bonus)
+	}
+	return bonus, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"bonus/controllers"
+	middleware "bonus/middlewares"
 	"bonus/models"
 
 	"github.com/gin-contrib/cors"
@@ -30,7 +31,8 @@ func main() {
 
 	// Koneksi ke DB MySQL
 	dsn := "root@tcp(127.0.0.1:3306)/order_bonus_api?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError => pelanggaran unique index dikembalikan sebagai gorm.ErrDuplicatedKey
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Gagal koneksi ke database: ", err)
 	}
@@ -38,16 +40,38 @@ func main() {
 	// Migrasi model
 	db.AutoMigrate(
 		&models.Employee{},
+		&models.KPITemplate{},
 		&models.KPI{},
 		&models.Criterion{},
 		&models.Evaluation{},
 		&models.KPIEvaluation{},
+		&models.AchievementLevel{},
+		&models.AchievementLevelLabel{},
 		&models.KpiCategory{},
 		&models.Kondite{},
+		&models.EvaluationPeriod{},
+		&models.PeriodUnlockLog{},
+		&models.KalibrasiSnapshot{},
+		&models.ScaleTable{},
+		&models.ScaleBand{},
+		&models.EmployeeLeave{},
+		&models.EligibilityRule{},
+		&models.KonditeCategory{},
+		&models.Reward{},
+		&models.RewardCategory{},
+		&models.CalibrationOverride{},
+		&models.CalibrationSession{},
+		&models.CalibrationSessionParticipant{},
 	)
 
+	// Pindahkan definisi KPI lama (kolom di tabel kpis) ke template KPI
+	migrasiKPITemplate(db)
+
 	// Seed data admin setelah migrasi
 	seedAdmin(db)
+	seedEligibilityRules(db)
+	seedKonditeCategories(db)
+	seedAchievementLevels(db)
 
 	// Set DB di controllers
 	controllers.SetDB(db)
@@ -57,9 +81,64 @@ func main() {
 		// Bonus
 		api.POST("/bonus/calculate", controllers.CalculateBonus)
 		api.GET("/bonus", controllers.GetBonus)
+		api.POST("/bonus/simulate", controllers.SimulateBonus)
 
 		// Kalibrasi
 		api.GET("/kalibrasi", controllers.GetKalibrasi)
+		api.POST("/kalibrasi/pool", controllers.AllocateBonusPool)
+		api.POST("/kalibrasi/snapshots", middleware.JWTAuth(), controllers.FinalizeKalibrasi)
+		api.GET("/kalibrasi/snapshots", controllers.GetKalibrasiSnapshots)
+		api.GET("/kalibrasi/snapshots/:id", controllers.GetKalibrasiSnapshot)
+		api.GET("/kalibrasi/snapshots/:id/diff", controllers.DiffKalibrasiSnapshot)
+
+		// Override kalibrasi oleh komite
+		api.GET("/calibration-overrides", controllers.GetCalibrationOverrides)
+		api.POST("/calibration-overrides", middleware.JWTAuth(), controllers.CreateCalibrationOverride)
+		api.PUT("/calibration-overrides/:id", middleware.JWTAuth(), controllers.UpdateCalibrationOverride)
+		api.DELETE("/calibration-overrides/:id", middleware.JWTAuth(), controllers.DeleteCalibrationOverride)
+		api.POST("/calibration-overrides/:id/decision", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin, models.RoleHRD), controllers.DecideCalibrationOverride)
+
+		// Sesi (rapat) komite kalibrasi
+		api.GET("/calibration-sessions", controllers.GetCalibrationSessions)
+		api.POST("/calibration-sessions", middleware.JWTAuth(), controllers.OpenCalibrationSession)
+		api.GET("/calibration-sessions/:id", controllers.GetCalibrationSession)
+		api.GET("/calibration-sessions/:id/kalibrasi", controllers.GetSessionKalibrasi)
+		api.POST("/calibration-sessions/:id/overrides", middleware.JWTAuth(), controllers.RecordSessionOverride)
+		api.POST("/calibration-sessions/:id/close", middleware.JWTAuth(), controllers.CloseCalibrationSession)
+
+		// Periode evaluasi
+		api.GET("/periods", controllers.GetPeriods)
+		api.POST("/periods", controllers.CreatePeriod)
+		api.PUT("/periods/:id", controllers.UpdatePeriod)
+		api.DELETE("/periods/:id", controllers.DeletePeriod)
+		api.POST("/periods/:id/status", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin, models.RoleHRD), controllers.UpdatePeriodStatus)
+		api.POST("/periods/:id/unlock", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin), controllers.UnlockPeriod)
+		api.GET("/periods/:id/unlock-logs", controllers.GetPeriodUnlockLogs)
+
+		// Tabel skala bonus (versioned)
+		api.GET("/scale-tables", controllers.GetScaleTables)
+		api.GET("/scale-tables/:id", controllers.GetScaleTable)
+		api.POST("/scale-tables", controllers.CreateScaleTable)
+		api.PUT("/scale-tables/:id", controllers.UpdateScaleTable)
+		api.DELETE("/scale-tables/:id", controllers.DeleteScaleTable)
+
+		// Aturan eligibilitas bonus
+		api.GET("/eligibility-rules", controllers.GetEligibilityRules)
+		api.POST("/eligibility-rules", controllers.CreateEligibilityRule)
+		api.PUT("/eligibility-rules/:id", controllers.UpdateEligibilityRule)
+		api.DELETE("/eligibility-rules/:id", controllers.DeleteEligibilityRule)
+
+		// Kriteria penilaian (bobot dipakai pada bonus & kalibrasi)
+		api.GET("/criteria", controllers.GetCriteria)
+		api.POST("/criteria", controllers.CreateCriterion)
+		api.PUT("/criteria/:id", controllers.UpdateCriterion)
+		api.DELETE("/criteria/:id", controllers.DeleteCriterion)
+
+		// Evaluasi pegawai per kriteria
+		api.GET("/evaluations", controllers.GetEvaluations)
+		api.POST("/evaluations", controllers.CreateEvaluation)
+		api.PUT("/evaluations/:id", controllers.UpdateEvaluation)
+		api.DELETE("/evaluations/:id", controllers.DeleteEvaluation)
 
 		// Login (auth)
 		api.POST("/login", controllers.Login)
@@ -69,6 +148,15 @@ func main() {
 		api.POST("/kpis", controllers.CreateKPI)
 		api.PUT("/kpis/:id", controllers.UpdateKPI)
 		api.DELETE("/kpis/:id", controllers.DeleteKPI)
+		api.POST("/kpis/:id/actual", controllers.SubmitKPIActual)
+
+		// Pustaka template KPI
+		api.GET("/kpi-templates", controllers.GetKPITemplates)
+		api.POST("/kpi-templates", controllers.CreateKPITemplate)
+		api.GET("/kpi-templates/:id", controllers.GetKPITemplate)
+		api.PUT("/kpi-templates/:id", controllers.UpdateKPITemplate)
+		api.DELETE("/kpi-templates/:id", controllers.DeleteKPITemplate)
+		api.POST("/kpi-templates/:id/assign", controllers.AssignKPITemplate)
 
 		// Kategori KPI
 		api.GET("/kpi-categories", controllers.GetKpiCategories)
@@ -78,19 +166,57 @@ func main() {
 
 		// Penilaian KPI
 		api.GET("/kpi_achievement_list", controllers.GetKPIAchievementList)
+
+		// Tingkatan capaian penilaian KPI
+		api.GET("/achievement-levels", controllers.GetAchievementLevels)
+		api.POST("/achievement-levels", controllers.CreateAchievementLevel)
+		api.PUT("/achievement-levels/:id", controllers.UpdateAchievementLevel)
+		api.DELETE("/achievement-levels/:id", controllers.DeleteAchievementLevel)
 		api.GET("/kpi_evaluations", controllers.GetAllKPIEvaluations)
 		api.POST("/kpi_evaluations", controllers.CreateKPIEvaluation)
+		api.POST("/kpi_evaluations/:id/decision", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin, models.RoleHRD, models.RoleManager), controllers.DecideKPIEvaluation)
 
 		// Employee
 		api.GET("/employees", controllers.GetEmployees)
 		api.POST("/employees", controllers.CreateEmployee)
 		api.PUT("/employees/:id", controllers.UpdateEmployee)
 		api.DELETE("/employees/:id", controllers.DeleteEmployee)
+		api.GET("/employees/:id/leaves", controllers.GetEmployeeLeaves)
+		api.POST("/employees/:id/leaves", controllers.CreateEmployeeLeave)
+		api.DELETE("/employee-leaves/:id", controllers.DeleteEmployeeLeave)
+
+		// Sheet KPI pegawai (validasi bobot)
+		api.GET("/employees/:id/kpi-sheet", controllers.GetKPISheet)
+		api.POST("/employees/:id/kpi-sheet/validate", controllers.ValidateKPISheet)
 		// Kondite
 		api.GET("/kondites", controllers.GetKondites)
 		api.POST("/kondites", controllers.CreateKondite)
 		api.PUT("/kondites/:id", controllers.UpdateKondite)
 		api.DELETE("/kondites/:id", controllers.DeleteKondite)
+
+		// Alur persetujuan & banding kondite
+		api.POST("/kondites/:id/submit", controllers.SubmitKondite)
+		api.POST("/kondites/:id/appeal", middleware.JWTAuth(), controllers.AppealKondite)
+		api.POST("/kondites/:id/decision", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin, models.RoleHRD), controllers.DecideKondite)
+
+		// Reward (penambah poin)
+		api.GET("/rewards", controllers.GetRewards)
+		api.POST("/rewards", controllers.CreateReward)
+		api.PUT("/rewards/:id", controllers.UpdateReward)
+		api.DELETE("/rewards/:id", controllers.DeleteReward)
+		api.POST("/rewards/:id/decision", middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin, models.RoleHRD), controllers.DecideReward)
+
+		// Katalog kategori reward
+		api.GET("/reward-categories", controllers.GetRewardCategories)
+		api.POST("/reward-categories", controllers.CreateRewardCategory)
+		api.PUT("/reward-categories/:id", controllers.UpdateRewardCategory)
+		api.DELETE("/reward-categories/:id", controllers.DeleteRewardCategory)
+
+		// Katalog kategori kondite
+		api.GET("/kondite-categories", controllers.GetKonditeCategories)
+		api.POST("/kondite-categories", controllers.CreateKonditeCategory)
+		api.PUT("/kondite-categories/:id", controllers.UpdateKonditeCategory)
+		api.DELETE("/kondite-categories/:id", controllers.DeleteKonditeCategory)
 	}
 
 	// Jalankan server di port 8080
@@ -123,3 +249,137 @@ func seedAdmin(db *gorm.DB) {
 		log.Println("Admin default sudah ada, tidak perlu seed ulang.")
 	}
 }
+
+// seedEligibilityRules membuat aturan eligibilitas default jika belum ada aturan sama sekali
+func seedEligibilityRules(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&models.EligibilityRule{}).Count(&count).Error; err != nil {
+		log.Println("Gagal cek aturan eligibilitas:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	rules := []models.EligibilityRule{
+		{Name: "Admin tidak menerima bonus", Type: models.RuleTypeRole, Value: "admin", Active: true},
+		{Name: "Pegawai masa percobaan", Type: models.RuleTypeEmploymentStatus, Value: "probation", Active: true},
+		{Name: "Sedang menjalani SP3", Type: models.RuleTypeKonditeCategory, Value: "SP3", Active: true},
+		{Name: "Resign sebelum tanggal pembayaran", Type: models.RuleTypeResignedBeforePayout, Active: true},
+	}
+	if err := db.Create(&rules).Error; err != nil {
+		log.Println("Gagal membuat aturan eligibilitas default:", err)
+		return
+	}
+	log.Println("Aturan eligibilitas default berhasil dibuat")
+}
+
+// seedKonditeCategories membuat katalog kategori kondite default jika katalog masih kosong
+func seedKonditeCategories(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&models.KonditeCategory{}).Count(&count).Error; err != nil {
+		log.Println("Gagal cek kategori kondite:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	// Urutan penting: kategori tujuan eskalasi dibuat lebih dulu
+	categories := []models.KonditeCategory{
+		{Code: "SP3", Name: "Surat Peringatan 3", DefaultMinPoint: 1, DefaultDurationDays: 180},
+		{Code: "SP2", Name: "Surat Peringatan 2", DefaultMinPoint: 0.5, DefaultDurationDays: 180,
+			EscalateTo: "SP3", EscalationCount: 2, EscalationWindowMonths: 6},
+		{Code: "SP1", Name: "Surat Peringatan 1", DefaultMinPoint: 0.25, DefaultDurationDays: 180,
+			EscalateTo: "SP2", EscalationCount: 2, EscalationWindowMonths: 6},
+		{Code: "TL", Name: "Teguran Lisan", DefaultMinPoint: 0.1, DefaultDurationDays: 90,
+			EscalateTo: "SP1", EscalationCount: 3, EscalationWindowMonths: 6},
+	}
+	if err := db.Create(&categories).Error; err != nil {
+		log.Println("Gagal membuat kategori kondite default:", err)
+		return
+	}
+	log.Println("Kategori kondite default berhasil dibuat")
+}
+
+// seedAchievementLevels membuat tingkatan capaian default (code sama dengan daftar lama "poor 1" dst.)
+// jika tabel masih kosong
+func seedAchievementLevels(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&models.AchievementLevel{}).Count(&count).Error; err != nil {
+		log.Println("Gagal cek tingkatan capaian:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	label := func(id, en string) []models.AchievementLevelLabel {
+		return []models.AchievementLevelLabel{{Language: "id", Label: id}, {Language: "en", Label: en}}
+	}
+	levels := []models.AchievementLevel{
+		{Code: "poor 1", Point: 1, SortOrder: 1, Labels: label("Kurang", "Poor")},
+		{Code: "fair 2", Point: 2, SortOrder: 2, Labels: label("Cukup", "Fair")},
+		{Code: "good 3", Point: 3, SortOrder: 3, Labels: label("Baik", "Good")},
+		{Code: "outstanding 4", Point: 4, SortOrder: 4, Labels: label("Sangat Baik", "Outstanding")},
+		{Code: "exceptional 5", Point: 5, SortOrder: 5, Labels: label("Istimewa", "Exceptional")},
+	}
+	if err := db.Create(&levels).Error; err != nil {
+		log.Println("Gagal membuat tingkatan capaian default:", err)
+		return
+	}
+	log.Println("Tingkatan capaian default berhasil dibuat")
+}
+
+// migrasiKPITemplate membuat template KPI dari kolom definisi lama pada tabel kpis (title, category,
+// weight, target, rubrik) untuk baris yang belum memiliki template_id. KPI dengan definisi identik
+// memakai template yang sama. Kolom lama dibiarkan (tidak dihapus) sebagai arsip.
+func migrasiKPITemplate(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.KPI{}, "title") {
+		return
+	}
+
+	// Kolom definisi lama sekaligus kunci pengelompokan template
+	type DefinisiKPI struct {
+		Title, Category, Target, Poor, Fair, Good, Outstanding, Exceptional string
+		Weight                                                              float64
+	}
+	var legacy []struct {
+		ID uint
+		DefinisiKPI
+	}
+	err := db.Table("kpis").
+		Select("id, title, category, weight, target, poor, fair, good, outstanding, exceptional").
+		Where("(template_id IS NULL OR template_id = 0) AND deleted_at IS NULL").
+		Scan(&legacy).Error
+	if err != nil {
+		log.Println("Gagal membaca KPI lama:", err)
+		return
+	}
+	if len(legacy) == 0 {
+		return
+	}
+
+	templateID := map[DefinisiKPI]uint{}
+	for _, row := range legacy {
+		d := row.DefinisiKPI
+		id, ok := templateID[d]
+		if !ok {
+			template := models.KPITemplate{
+				Title: d.Title, Category: d.Category, Weight: d.Weight, Target: d.Target,
+				Poor: d.Poor, Fair: d.Fair, Good: d.Good, Outstanding: d.Outstanding, Exceptional: d.Exceptional,
+			}
+			if err := db.Create(&template).Error; err != nil {
+				log.Println("Gagal membuat template KPI:", err)
+				return
+			}
+			id = template.ID
+			templateID[d] = id
+		}
+		if err := db.Table("kpis").Where("id = ?", row.ID).Update("template_id", id).Error; err != nil {
+			log.Println("Gagal menghubungkan KPI ke template:", err)
+			return
+		}
+	}
+	log.Printf("%d KPI lama dipindahkan ke %d template KPI", len(legacy), len(templateID))
+}
@@ -45,3 +45,17 @@ func JWTAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole memastikan role pada token (diset oleh JWTAuth) termasuk salah satu role yang diizinkan.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak untuk role ini"})
+	}
+}
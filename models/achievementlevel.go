@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// AchievementLevel merepresentasikan tingkatan capaian pada penilaian KPI (mis. "good 3" => 3 poin).
+// Boleh berisi langkah setengah (mis. 3.5).
+type AchievementLevel struct {
+	gorm.Model
+	Code      string  `json:"code" gorm:"uniqueIndex;size:50"` // nilai yang dikirim pada penilaian KPI
+	Point     float64 `json:"point"`
+	SortOrder int     `json:"sort_order"`
+
+	Labels []AchievementLevelLabel `json:"labels" gorm:"foreignKey:AchievementLevelID"`
+}
+
+// AchievementLevelLabel => label tingkatan capaian untuk satu bahasa
+type AchievementLevelLabel struct {
+	gorm.Model
+	AchievementLevelID uint   `json:"achievement_level_id"`
+	Language           string `json:"language"` // mis. "id", "en"
+	Label              string `json:"label"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status override kalibrasi (siklus: pending -> approved / rejected)
+const (
+	OverrideStatusPending  = "pending"
+	OverrideStatusApproved = "approved"
+	OverrideStatusRejected = "rejected"
+)
+
+// CalibrationOverride mencatat keputusan komite kalibrasi untuk mengubah skala atau KPI akhir
+// seorang pegawai pada suatu periode. Hanya override yang approved yang diterapkan.
+type CalibrationOverride struct {
+	gorm.Model
+	PeriodID   uint     `json:"period_id"`
+	EmployeeID uint     `json:"employee_id"`
+	Scale      string   `json:"scale"`     // label skala pengganti (opsional)
+	FinalKPI   *float64 `json:"final_kpi"` // KPI akhir pengganti (opsional)
+	Reason     string   `json:"reason"`
+	SessionID  uint     `json:"session_id"` // sesi kalibrasi tempat keputusan diambil (0 = di luar sesi)
+
+	CreatedBy  uint       `json:"created_by"` // employee_id pengusul
+	Status     string     `json:"status" gorm:"default:pending"`
+	ApprovedBy uint       `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+
+	// Relasi ke Employee
+	Employee Employee `json:"employee" gorm:"foreignKey:EmployeeID"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status sesi kalibrasi
+const (
+	SessionStatusOpen   = "open"
+	SessionStatusClosed = "closed"
+)
+
+// CalibrationSession merepresentasikan rapat komite kalibrasi yang meninjau sebagian pegawai
+// (berdasarkan departemen dan/atau atasan) pada satu periode.
+type CalibrationSession struct {
+	gorm.Model
+	PeriodID uint   `json:"period_id"`
+	Name     string `json:"name"`
+
+	// Cakupan pegawai (kosong = semua pegawai)
+	Department string `json:"department"`
+	ManagerID  uint   `json:"manager_id"`
+
+	Status   string     `json:"status" gorm:"default:open"`
+	OpenedBy uint       `json:"opened_by"`
+	ClosedBy uint       `json:"closed_by"`
+	ClosedAt *time.Time `json:"closed_at"`
+	Minutes  string     `json:"minutes" gorm:"type:longtext"` // notulen, dibuat saat sesi ditutup
+
+	Participants []CalibrationSessionParticipant `json:"participants" gorm:"foreignKey:SessionID"`
+}
+
+// CalibrationSessionParticipant => peserta (manajer / anggota komite) sesi kalibrasi
+type CalibrationSessionParticipant struct {
+	gorm.Model
+	SessionID  uint `json:"session_id"`
+	EmployeeID uint `json:"employee_id"`
+
+	Employee Employee `json:"employee" gorm:"foreignKey:EmployeeID"`
+}
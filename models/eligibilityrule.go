@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// Jenis aturan eligibilitas bonus
+const (
+	RuleTypeRole                 = "role"                   // Value: daftar role yang dikecualikan, mis. "admin,HRD"
+	RuleTypeTenure               = "tenure"                 // Value: minimal masa kerja (bulan) di akhir periode
+	RuleTypeKonditeCategory      = "kondite_category"       // Value: kategori kondite yang menggugurkan, mis. "SP3"
+	RuleTypeEmploymentStatus     = "employment_status"      // Value: status kepegawaian yang dikecualikan, mis. "probation"
+	RuleTypeResignedBeforePayout = "resigned_before_payout" // berhenti sebelum tanggal pembayaran bonus periode
+	RuleTypeMinFinalKPI          = "min_final_kpi"          // Value: minimal KPI setelah kalibrasi, mis. "2"
+)
+
+// EligibilityRule merepresentasikan aturan yang harus dipenuhi pegawai agar mendapat bonus.
+type EligibilityRule struct {
+	gorm.Model
+	Name        string `json:"name"` // ditampilkan sebagai alasan jika pegawai tidak eligible
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Active      bool   `json:"active"`
+	Description string `json:"description"`
+}
@@ -1,7 +1,19 @@
 // models/employee.go
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role pegawai yang dipakai untuk otorisasi endpoint
+const (
+	RoleAdmin   = "admin"
+	RoleHRD     = "HRD"
+	RoleManager = "manager"
+	RolePegawai = "pegawai"
+)
 
 type Employee struct {
 	gorm.Model
@@ -10,4 +22,14 @@ type Employee struct {
 	Password string `json:"password"` // Simpan hash password
 	Role     string `json:"role"`     // Contoh: admin, HRD, manager, pegawai
 	Salary   int    `json:"salary"`   // Tambahkan field gaji
+
+	Department string `json:"department"` // Departemen (dipakai untuk sub-pool bonus)
+	ManagerID  *uint  `json:"manager_id"` // Atasan langsung (dipakai untuk cakupan sesi kalibrasi)
+
+	// Status kepegawaian: permanent, contract, probation, resigned
+	EmploymentStatus string `json:"employment_status"`
+
+	// Masa kerja (dipakai untuk prorata bonus)
+	HireDate        *time.Time `json:"hire_date"`
+	TerminationDate *time.Time `json:"termination_date"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmployeeLeave menyimpan interval cuti pegawai.
+// Cuti tidak dibayar (Unpaid) mengurangi hari kerja yang dihitung untuk prorata bonus.
+type EmployeeLeave struct {
+	gorm.Model
+	EmployeeID  uint      `json:"employee_id"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Unpaid      bool      `json:"unpaid"`
+	Description string    `json:"description"`
+}
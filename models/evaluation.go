@@ -9,6 +9,7 @@ type Evaluation struct {
 	CriterionID uint    `json:"criterion_id"` // ID kriteria yang digunakan dalam evaluasi
 	Score       float64 `json:"score"`        // Nilai atau skor yang diberikan
 	Comments    string  `json:"comments"`     // Komentar tambahan (opsional)
+	PeriodID    uint    `json:"period_id"`    // Relasi ke periode evaluasi (0 => tanpa periode)
 
 	// Relasi (opsional) untuk mendapatkan data lengkap pegawai dan kriteria
 	Employee  Employee  `gorm:"foreignKey:EmployeeID" json:"employee"`
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status periode evaluasi (siklus: draft -> open -> calibration -> locked)
+const (
+	PeriodStatusDraft       = "draft"       // periode disiapkan (KPI, target, dsb.)
+	PeriodStatusOpen        = "open"        // data penilaian masih bisa diinput
+	PeriodStatusCalibration = "calibration" // penilaian selesai, proses kalibrasi
+	PeriodStatusLocked      = "locked"      // bonus sudah dibayar, data tidak bisa diubah
+)
+
+// Mode perhitungan multiplier bonus dari tabel skala
+const (
+	ScaleModeStep   = "step"   // multiplier mengikuti band (tangga)
+	ScaleModeLinear = "linear" // multiplier diinterpolasi linear di antara batas bawah band
+)
+
+// Aturan agregasi penilaian KPI (KPIEvaluation) approved menjadi skor KPI
+const (
+	KPIEvaluationRuleLatest  = "latest"  // penilaian approved terakhir
+	KPIEvaluationRuleAverage = "average" // rata-rata semua penilaian approved
+	KPIEvaluationRuleMax     = "max"     // penilaian tertinggi
+	KPIEvaluationRuleMin     = "min"     // penilaian terendah
+)
+
+// EvaluationPeriod merepresentasikan periode penilaian (tahun fiskal / semester / kuartal).
+type EvaluationPeriod struct {
+	gorm.Model
+	Name      string    `json:"name"` // Contoh: "FY 2025", "Semester 1 2025", "Q3 2025"
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Status    string    `json:"status" gorm:"default:draft"`
+	ScaleMode string    `json:"scale_mode" gorm:"default:step"`
+
+	PayoutDate *time.Time `json:"payout_date"` // tanggal pembayaran bonus
+
+	// Jika true, pengurang poin kondite diprorata sesuai jumlah hari overlap dengan periode
+	ProrateKondite bool `json:"prorate_kondite"`
+
+	// Batas kalibrasi (nil = tanpa batas)
+	MaxDeduction    *float64 `json:"max_deduction"`     // maksimal total pengurang poin kondite
+	MaxRewardPoints *float64 `json:"max_reward_points"` // maksimal total penambah poin reward
+	MaxFinalKPI     *float64 `json:"max_final_kpi"`     // plafon KPI setelah kalibrasi (mis. 5.0)
+
+	KPIEvaluationRule string `json:"kpi_evaluation_rule" gorm:"default:latest"`
+}
+
+// PeriodUnlockLog mencatat pembukaan kembali periode yang sudah dikunci.
+type PeriodUnlockLog struct {
+	gorm.Model
+	PeriodID   uint   `json:"period_id"`
+	EmployeeID uint   `json:"employee_id"` // admin yang membuka kunci
+	Reason     string `json:"reason"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+}
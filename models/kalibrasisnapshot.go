@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// KalibrasiSnapshot menyimpan hasil kalibrasi yang sudah difinalisasi (tidak berubah lagi).
+// Setiap finalisasi pada periode yang sama menghasilkan versi baru.
+type KalibrasiSnapshot struct {
+	gorm.Model
+	PeriodID    uint   `json:"period_id" gorm:"uniqueIndex:idx_snapshot_periode_versi"`
+	Version     int    `json:"version" gorm:"uniqueIndex:idx_snapshot_periode_versi"`
+	FinalizedBy uint   `json:"finalized_by"` // employee_id yang melakukan finalisasi
+	Note        string `json:"note"`
+
+	// Disimpan dalam bentuk JSON
+	Results string `json:"-" gorm:"type:longtext"` // []KalibrasiResponse
+	Inputs  string `json:"-" gorm:"type:longtext"` // KPI, kondite, tabel skala, gaji
+}
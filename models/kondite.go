@@ -6,6 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// Status kondite (siklus: draft -> pending -> active -> appealed -> upheld / revoked)
+const (
+	KonditeStatusDraft    = "draft"    // masih disusun, belum diajukan
+	KonditeStatusPending  = "pending"  // menunggu persetujuan HR
+	KonditeStatusActive   = "active"   // disetujui HR, berlaku
+	KonditeStatusAppealed = "appealed" // pegawai mengajukan banding
+	KonditeStatusUpheld   = "upheld"   // banding ditolak, kondite tetap berlaku
+	KonditeStatusRevoked  = "revoked"  // ditolak / dibatalkan, tidak berlaku
+)
+
 type Kondite struct {
 	gorm.Model
 	EmployeeID  uint      `json:"employee_id"`
@@ -13,7 +23,19 @@ type Kondite struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 	Description string    `json:"description"`
-	MinPoint    float64     `json:"min_point"`
+	MinPoint    float64   `json:"min_point"`
+	PeriodID    uint      `json:"period_id"`
+
+	// Kategori awal sebelum dinaikkan otomatis oleh aturan eskalasi (kosong jika tidak ada eskalasi)
+	EscalatedFrom string `json:"escalated_from"`
+
+	// Alur persetujuan & banding. Data lama (sebelum alur ini ada) dianggap active.
+	Status       string     `json:"status" gorm:"default:active"`
+	AppealReason string     `json:"appeal_reason"`
+	AppealedAt   *time.Time `json:"appealed_at"`
+	DecidedBy    uint       `json:"decided_by"`
+	DecisionNote string     `json:"decision_note"`
+	DecidedAt    *time.Time `json:"decided_at"`
 
 	// Relasi ke Employee
 	Employee Employee `json:"employee" gorm:"foreignKey:EmployeeID"`
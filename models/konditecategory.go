@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// KonditeCategory merepresentasikan katalog kategori disiplin (Teguran Lisan, SP1, SP2, SP3, dsb.)
+// beserta nilai default dan aturan eskalasinya.
+type KonditeCategory struct {
+	gorm.Model
+	Code                string  `json:"code" gorm:"uniqueIndex;size:50"` // mis. "SP1"
+	Name                string  `json:"name"`
+	DefaultMinPoint     float64 `json:"default_min_point"`     // pengurang poin default
+	DefaultDurationDays int     `json:"default_duration_days"` // masa berlaku default (hari)
+
+	// Eskalasi: jika pegawai menerima kategori ini untuk ke-EscalationCount kalinya dalam
+	// EscalationWindowMonths bulan, kondite baru otomatis dinaikkan ke EscalateTo.
+	EscalateTo             string `json:"escalate_to"`
+	EscalationCount        int    `json:"escalation_count"`
+	EscalationWindowMonths int    `json:"escalation_window_months"`
+}
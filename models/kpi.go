@@ -1,20 +1,27 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// KPI merepresentasikan penugasan KPITemplate ke seorang pegawai pada suatu periode,
+// beserta hasil penilaiannya. Bobot & target mengikuti template kecuali di-override.
 type KPI struct {
 	gorm.Model
-	Title        string  `json:"title"`
-	Category     string  `json:"category"`
-	Weight       float64 `json:"weight"`
-	Target       string  `json:"target"`
-	Poor         string  `json:"poor"`
-	Fair         string  `json:"fair"`
-	Good         string  `json:"good"`
-	Outstanding  string  `json:"outstanding"`
-	Exceptional  string  `json:"exceptional"`
+	TemplateID     uint     `json:"template_id" gorm:"index"`
+	WeightOverride *float64 `json:"weight_override"` // nil => bobot template
+	TargetOverride *string  `json:"target_override"` // nil => target template
+
+	TargetValueOverride *float64   `json:"target_value_override"` // nil => target numerik template
+	Actual              *float64   `json:"actual"`                // nilai aktual (skor dihitung dari rubrik)
+	ActualSubmittedAt   *time.Time `json:"actual_submitted_at"`
 
 	Score      float64 `json:"score"`       // Nilai KPI yang diinput pegawai
 	Validated  bool    `json:"validated"`   // Validasi oleh atasan
 	EmployeeID uint    `json:"employee_id"` // Relasi ke pegawai
+	PeriodID   uint    `json:"period_id"`   // Relasi ke periode evaluasi
+
+	Template KPITemplate `json:"template" gorm:"foreignKey:TemplateID"`
 }
@@ -7,4 +7,7 @@ type KpiCategory struct {
 	gorm.Model
 	Name        string `json:"name"`
 	Description string `json:"description"`
+
+	// Target total bobot kategori ini pada sheet KPI pegawai (persen, nil = tanpa target)
+	TargetWeight *float64 `json:"target_weight"`
 }
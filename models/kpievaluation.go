@@ -1,12 +1,29 @@
 // models/kpi_evaluation.go
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status penilaian KPI (siklus: pending -> approved / rejected)
+const (
+	KPIEvaluationStatusPending  = "pending"
+	KPIEvaluationStatusApproved = "approved"
+	KPIEvaluationStatusRejected = "rejected"
+)
 
 type KPIEvaluation struct {
 	gorm.Model
-	EmployeeID  uint   `json:"employee_id"`
-	KPIID       uint   `json:"kpi_id"`
-	Achievement string `json:"achievement"`
+	EmployeeID  uint    `json:"employee_id"`
+	KPIID       uint    `json:"kpi_id"`
+	Achievement string  `json:"achievement"`
 	Point       float64 `json:"point"` // menampung nilai numeric dari achievement
+	PeriodID    uint    `json:"period_id"`
+
+	// Hanya penilaian approved yang membentuk skor KPI. Data lama dianggap approved.
+	Status     string     `json:"status" gorm:"default:approved"`
+	ApprovedBy uint       `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
 }
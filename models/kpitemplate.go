@@ -0,0 +1,34 @@
+package models
+
+import "gorm.io/gorm"
+
+// Mode rubrik untuk menghitung skor 1–5 dari nilai aktual
+const (
+	RubricHigherIsBetter  = "higher_is_better"  // semakin besar semakin baik
+	RubricLowerIsBetter   = "lower_is_better"   // semakin kecil semakin baik
+	RubricPercentOfTarget = "percent_of_target" // aktual / target * 100, semakin besar semakin baik
+)
+
+// KPITemplate merepresentasikan definisi KPI pada pustaka KPI (judul, kategori, bobot, target & rubrik).
+// Satu template dapat ditugaskan ke banyak pegawai melalui KPI (assignment).
+type KPITemplate struct {
+	gorm.Model
+	Title       string  `json:"title"`
+	Category    string  `json:"category"` // Perusahaan / Dept / Individu
+	Weight      float64 `json:"weight"`   // bobot default (persen)
+	Target      string  `json:"target"`   // target default
+	Poor        string  `json:"poor"`
+	Fair        string  `json:"fair"`
+	Good        string  `json:"good"`
+	Outstanding string  `json:"outstanding"`
+	Exceptional string  `json:"exceptional"`
+
+	// Rubrik terstruktur (opsional). Ambang = nilai minimal (atau maksimal untuk lower_is_better)
+	// untuk mencapai level tersebut; di bawah ambang Fair => Poor.
+	RubricMode           string   `json:"rubric_mode"`
+	TargetValue          *float64 `json:"target_value"` // target numerik untuk percent_of_target
+	FairThreshold        *float64 `json:"fair_threshold"`
+	GoodThreshold        *float64 `json:"good_threshold"`
+	OutstandingThreshold *float64 `json:"outstanding_threshold"`
+	ExceptionalThreshold *float64 `json:"exceptional_threshold"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status reward (siklus: pending -> approved / rejected)
+const (
+	RewardStatusPending  = "pending"  // menunggu persetujuan
+	RewardStatusApproved = "approved" // disetujui, ikut menambah poin
+	RewardStatusRejected = "rejected" // ditolak
+)
+
+// Reward merepresentasikan penambah poin (penghargaan inovasi, sertifikasi, dsb.)
+type Reward struct {
+	gorm.Model
+	EmployeeID  uint      `json:"employee_id"`
+	Category    string    `json:"category"` // code pada katalog RewardCategory
+	Date        time.Time `json:"date"`     // tanggal pencapaian
+	Description string    `json:"description"`
+	Points      float64   `json:"points"`
+	PeriodID    uint      `json:"period_id"`
+
+	Status     string     `json:"status" gorm:"default:pending"`
+	ApprovedBy uint       `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+
+	// Relasi ke Employee
+	Employee Employee `json:"employee" gorm:"foreignKey:EmployeeID"`
+}
+
+// RewardCategory merepresentasikan katalog kategori reward beserta poin default dan batasnya.
+type RewardCategory struct {
+	gorm.Model
+	Code          string  `json:"code" gorm:"uniqueIndex;size:50"` // mis. "INOVASI"
+	Name          string  `json:"name"`
+	DefaultPoints float64 `json:"default_points"`
+	MaxPoints     float64 `json:"max_points"` // batas total poin per pegawai per periode (0 = tanpa batas)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScaleTable merepresentasikan satu versi tabel skala bonus (Poor .. Exceptional).
+// Tabel yang dipakai untuk suatu periode adalah tabel dengan EffectiveFrom terakhir
+// yang tidak melewati tanggal mulai periode.
+type ScaleTable struct {
+	gorm.Model
+	Name          string      `json:"name"`
+	Version       int         `json:"version"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	Bands         []ScaleBand `json:"bands" gorm:"foreignKey:ScaleTableID"`
+}
+
+// ScaleBand merepresentasikan satu rentang pada tabel skala.
+// KPI akhir masuk ke band jika LowerBound <= KPI < UpperBound (UpperBound nil => tanpa batas atas).
+type ScaleBand struct {
+	gorm.Model
+	ScaleTableID uint     `json:"scale_table_id"`
+	Label        string   `json:"label"`
+	LowerBound   float64  `json:"lower_bound"`
+	UpperBound   *float64 `json:"upper_bound"`
+	Multiplier   float64  `json:"multiplier"`   // bonus = gaji * multiplier
+	FixedAmount  *float64 `json:"fixed_amount"` // jika diisi, bonus = nominal tetap ini
+}